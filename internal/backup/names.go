@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// backupPatterns match the backups written by esm: eve-backup-*.zip by
+// 'esm backup', backup_*.zip by copy, import, profile delete and watch.
+var backupPatterns = []string{"eve-backup-*.zip", "backup_*.zip"}
+
+// IsBackup reports whether name is the file name of a backup written by esm.
+func IsBackup(name string) bool {
+	for _, pattern := range backupPatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FindBackups returns the backup files in dirs, oldest first.
+func FindBackups(dirs []string) []string {
+	type found struct {
+		path    string
+		modTime int64
+	}
+	var backups []found
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		for _, pattern := range backupPatterns {
+			matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, path := range matches {
+				info, err := os.Stat(path)
+				if err != nil || info.IsDir() || seen[path] {
+					continue
+				}
+				seen[path] = true
+				backups = append(backups, found{path: path, modTime: info.ModTime().UnixNano()})
+			}
+		}
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].modTime < backups[j].modTime
+	})
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+// CharacterNames collects the character names recorded in the metadata of
+// the backups at paths. Later backups win, so that a renamed character gets
+// its latest name. Backups that cannot be read and names that were unknown
+// when the backup was made are skipped.
+func CharacterNames(paths []string) map[int64]string {
+	names := make(map[int64]string)
+	for _, path := range paths {
+		metadata, err := ReadBackup(path)
+		if err != nil {
+			continue
+		}
+		for _, c := range metadata.Characters {
+			if c.CharacterName == "" || strings.HasPrefix(c.CharacterName, "Unknown (") {
+				continue
+			}
+			names[c.CharacterID] = c.CharacterName
+		}
+	}
+	return names
+}
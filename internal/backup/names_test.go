@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCharacterNames(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceFile := filepath.Join(tempDir, "core_char_1.dat")
+	if err := os.WriteFile(sourceFile, []byte("settings"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// writeTestBackup creates a backup holding the given names, modified at modTime
+	writeTestBackup := func(name string, modTime time.Time, chars ...CharacterBackup) string {
+		path := filepath.Join(tempDir, name)
+		files := make(map[int64]string)
+		for _, c := range chars {
+			files[c.CharacterID] = sourceFile
+		}
+		if err := CreateBackup(path, chars, files); err != nil {
+			t.Fatalf("CreateBackup failed: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	now := time.Now()
+	newer := writeTestBackup("backup_1_new.zip", now,
+		CharacterBackup{CharacterID: 1, CharacterName: "New Name"},
+		CharacterBackup{CharacterID: 2, CharacterName: "Unknown (2)"})
+	older := writeTestBackup("eve-backup-old.zip", now.Add(-time.Hour),
+		CharacterBackup{CharacterID: 1, CharacterName: "Old Name"},
+		CharacterBackup{CharacterID: 3, CharacterName: "Third"})
+
+	// Neither a backup name nor a backup
+	if err := os.WriteFile(filepath.Join(tempDir, "other.zip"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(tempDir, "backup_broken.zip")
+	if err := os.WriteFile(broken, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(broken, now.Add(-time.Minute), now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := FindBackups([]string{tempDir, tempDir})
+	if len(paths) != 3 || paths[0] != older || paths[1] != broken || paths[2] != newer {
+		t.Fatalf("unexpected backups %v", paths)
+	}
+
+	names := CharacterNames(paths)
+	if len(names) != 2 {
+		t.Errorf("expected 2 names, got %v", names)
+	}
+	if names[1] != "New Name" {
+		t.Errorf("expected the newest backup to win, got %q", names[1])
+	}
+	if names[3] != "Third" {
+		t.Errorf("expected 'Third', got %q", names[3])
+	}
+}
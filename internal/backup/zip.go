@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,6 +16,8 @@ type Metadata struct {
 	CreatedAt  string            `json:"created_at"`
 	Version    string            `json:"version"`
 	Characters []CharacterBackup `json:"characters"`
+	Users      []UserBackup      `json:"users,omitempty"`
+	Profile    string            `json:"profile,omitempty"` // profile folder, for whole-profile backups
 }
 
 // CharacterBackup contains information about a backed up character.
@@ -25,11 +28,64 @@ type CharacterBackup struct {
 	FileName      string `json:"file_name"`
 }
 
+// UserBackup contains information about a backed up account user file.
+type UserBackup struct {
+	UserID       int64  `json:"user_id"`
+	OriginalPath string `json:"original_path"`
+	FileName     string `json:"file_name"`
+}
+
 const metadataFileName = "metadata.json"
-const backupVersion = "1.0"
+const backupVersion = "1.1"
 
 // CreateBackup creates a ZIP backup containing the specified character files.
-func CreateBackup(outputPath string, characters []CharacterBackup, files map[int64]string) (err error) {
+func CreateBackup(outputPath string, characters []CharacterBackup, files map[int64]string) error {
+	return CreateBackupWithUsers(outputPath, characters, files, nil, nil)
+}
+
+// CreateBackupWithUsers creates a ZIP backup containing the specified character
+// files and account user files.
+func CreateBackupWithUsers(outputPath string, characters []CharacterBackup, files map[int64]string,
+	users []UserBackup, userFiles map[int64]string) error {
+	entries := make(map[string]string, len(files)+len(userFiles))
+	for charID, filePath := range files {
+		entries[fmt.Sprintf("core_char_%d.dat", charID)] = filePath
+	}
+	for userID, filePath := range userFiles {
+		entries[fmt.Sprintf("core_user_%d.dat", userID)] = filePath
+	}
+
+	return writeBackup(outputPath, Metadata{Characters: characters, Users: users}, entries)
+}
+
+// CreateProfileBackup creates a ZIP backup of a whole settings profile folder.
+// Every file in the folder is stored under its relative path, so ExtractAll
+// recreates the profile; the character and user files are also listed in the
+// metadata so they can be restored individually.
+func CreateProfileBackup(outputPath, profileDir string, characters []CharacterBackup, users []UserBackup) error {
+	entries := make(map[string]string)
+	err := filepath.WalkDir(profileDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(profileDir, path)
+		if err != nil {
+			return err
+		}
+		entries[filepath.ToSlash(rel)] = path
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read profile folder: %w", err)
+	}
+
+	metadata := Metadata{Profile: profileDir, Characters: characters, Users: users}
+	return writeBackup(outputPath, metadata, entries)
+}
+
+// writeBackup creates a ZIP file holding metadata and the given entries,
+// which map names inside the archive to source file paths.
+func writeBackup(outputPath string, metadata Metadata, entries map[string]string) (err error) {
 	zipFile, err := os.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create backup file: %w", err)
@@ -47,12 +103,8 @@ func CreateBackup(outputPath string, characters []CharacterBackup, files map[int
 		}
 	}()
 
-	// Create metadata
-	metadata := Metadata{
-		CreatedAt:  time.Now().Format(time.RFC3339),
-		Version:    backupVersion,
-		Characters: characters,
-	}
+	metadata.CreatedAt = time.Now().Format(time.RFC3339)
+	metadata.Version = backupVersion
 
 	// Write metadata
 	metadataWriter, err := zipWriter.Create(metadataFileName)
@@ -63,11 +115,10 @@ func CreateBackup(outputPath string, characters []CharacterBackup, files map[int
 		return fmt.Errorf("failed to write metadata: %w", err)
 	}
 
-	// Write character files
-	for charID, filePath := range files {
-		fileName := fmt.Sprintf("core_char_%d.dat", charID)
-		if err := addFileToZip(zipWriter, filePath, fileName); err != nil {
-			return fmt.Errorf("failed to add character %d to backup: %w", charID, err)
+	// Write settings files
+	for name, filePath := range entries {
+		if err := addFileToZip(zipWriter, filePath, name); err != nil {
+			return fmt.Errorf("failed to add %s to backup: %w", name, err)
 		}
 	}
 
@@ -129,7 +180,27 @@ func ExtractCharacter(backupPath string, charID int64, destPath string) error {
 	return fmt.Errorf("character %d not found in backup", charID)
 }
 
-// ExtractAll extracts all character settings from a backup to a directory.
+// ExtractUser extracts a specific account user file from a backup.
+func ExtractUser(backupPath string, userID int64, destPath string) error {
+	zipReader, err := zip.OpenReader(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer func() {
+		_ = zipReader.Close()
+	}()
+
+	fileName := fmt.Sprintf("core_user_%d.dat", userID)
+	for _, file := range zipReader.File {
+		if file.Name == fileName {
+			return extractFile(file, destPath)
+		}
+	}
+
+	return fmt.Errorf("user %d not found in backup", userID)
+}
+
+// ExtractAll extracts all character and user settings from a backup to a directory.
 func ExtractAll(backupPath, destDir string) error {
 	zipReader, err := zip.OpenReader(backupPath)
 	if err != nil {
@@ -131,6 +131,59 @@ func TestExtractCharacterNotFound(t *testing.T) {
 	}
 }
 
+func TestBackupWithUsers(t *testing.T) {
+	tempDir := t.TempDir()
+
+	charFile := filepath.Join(tempDir, "core_char_111.dat")
+	userFile := filepath.Join(tempDir, "core_user_999.dat")
+	if err := os.WriteFile(charFile, []byte("char"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(userFile, []byte("user"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	backupPath := filepath.Join(tempDir, "test-backup.zip")
+	chars := []CharacterBackup{
+		{CharacterID: 111, CharacterName: "Char1", OriginalPath: charFile, FileName: "core_char_111.dat"},
+	}
+	users := []UserBackup{
+		{UserID: 999, OriginalPath: userFile, FileName: "core_user_999.dat"},
+	}
+
+	err := CreateBackupWithUsers(backupPath, chars, map[int64]string{111: charFile},
+		users, map[int64]string{999: userFile})
+	if err != nil {
+		t.Fatalf("CreateBackupWithUsers failed: %v", err)
+	}
+
+	metadata, err := ReadBackup(backupPath)
+	if err != nil {
+		t.Fatalf("ReadBackup failed: %v", err)
+	}
+
+	if len(metadata.Users) != 1 || metadata.Users[0].UserID != 999 {
+		t.Errorf("expected user 999 in metadata, got %+v", metadata.Users)
+	}
+
+	extractPath := filepath.Join(tempDir, "extracted", "core_user_999.dat")
+	if err := ExtractUser(backupPath, 999, extractPath); err != nil {
+		t.Fatalf("ExtractUser failed: %v", err)
+	}
+
+	extracted, err := os.ReadFile(extractPath)
+	if err != nil {
+		t.Fatalf("failed to read extracted file: %v", err)
+	}
+	if string(extracted) != "user" {
+		t.Errorf("extracted content mismatch: got %s, want user", extracted)
+	}
+
+	if err := ExtractUser(backupPath, 111, extractPath); err == nil {
+		t.Error("expected error for non-existent user")
+	}
+}
+
 func TestExtractAll(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -183,3 +236,52 @@ func TestReadBackupInvalidFile(t *testing.T) {
 		t.Error("expected error for non-existent file")
 	}
 }
+
+func TestCreateProfileBackup(t *testing.T) {
+	tempDir := t.TempDir()
+	profileDir := filepath.Join(tempDir, "settings_PvP")
+	files := map[string]string{
+		"core_char_111.dat":    "char",
+		"core_user_999.dat":    "user",
+		"prefs.ini":            "prefs",
+		"overviews/fleet.yaml": "overview",
+	}
+	for name, content := range files {
+		path := filepath.Join(profileDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	backupPath := filepath.Join(tempDir, "profile.zip")
+	chars := []CharacterBackup{{CharacterID: 111, OriginalPath: filepath.Join(profileDir, "core_char_111.dat"), FileName: "core_char_111.dat"}}
+	if err := CreateProfileBackup(backupPath, profileDir, chars, nil); err != nil {
+		t.Fatalf("CreateProfileBackup failed: %v", err)
+	}
+
+	metadata, err := ReadBackup(backupPath)
+	if err != nil {
+		t.Fatalf("ReadBackup failed: %v", err)
+	}
+	if metadata.Profile != profileDir || len(metadata.Characters) != 1 {
+		t.Errorf("unexpected metadata: %+v", metadata)
+	}
+
+	restored := filepath.Join(tempDir, "restored")
+	if err := ExtractAll(backupPath, restored); err != nil {
+		t.Fatalf("ExtractAll failed: %v", err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(restored, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("missing %s in backup: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %s, want %s", name, data, content)
+		}
+	}
+}
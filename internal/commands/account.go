@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/spf13/cobra"
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage character to account pairings",
+	Long: `Manage which account user file (core_user_*.dat) owns each character.
+
+Pairings are inferred automatically from the times the client writes both
+files. Use link to record a pairing yourself; linked pairings always take
+precedence over inferred ones.`,
+}
+
+var accountLinkCmd = &cobra.Command{
+	Use:   "link <character> <user-id>",
+	Short: "Pair a character with an account user file",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAccountLink,
+}
+
+var accountUnlinkCmd = &cobra.Command{
+	Use:   "unlink <character>",
+	Short: "Remove a user-supplied pairing",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAccountUnlink,
+}
+
+func init() {
+	accountCmd.AddCommand(accountLinkCmd)
+	accountCmd.AddCommand(accountUnlinkCmd)
+}
+
+func runAccountLink(cmd *cobra.Command, args []string) error {
+	esiClient := newESIClient()
+	charID, err := esiClient.ResolveCharacter(cmd.Context(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve character '%s': %w", args[0], err)
+	}
+
+	userID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || userID <= 0 {
+		return fmt.Errorf("invalid user ID '%s'", args[1])
+	}
+
+	store, err := openAccountStore()
+	if err != nil {
+		return err
+	}
+
+	store.Link(charID, userID)
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Linked %s (%d) to user %d\n", esiClient.GetCharacterNameOrFallback(cmd.Context(), charID), charID, userID)
+	return nil
+}
+
+func runAccountUnlink(cmd *cobra.Command, args []string) error {
+	esiClient := newESIClient()
+	charID, err := esiClient.ResolveCharacter(cmd.Context(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve character '%s': %w", args[0], err)
+	}
+
+	store, err := openAccountStore()
+	if err != nil {
+		return err
+	}
+
+	if !store.Unlink(charID) {
+		return fmt.Errorf("character %d has no user-supplied pairing", charID)
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Unlinked %s (%d)\n", esiClient.GetCharacterNameOrFallback(cmd.Context(), charID), charID)
+	return nil
+}
+
+// openAccountStore loads the account store from its default location.
+func openAccountStore() (*eve.AccountStore, error) {
+	path, err := eve.DefaultAccountStorePath()
+	if err != nil {
+		return nil, err
+	}
+	return eve.LoadAccountStore(path)
+}
+
+// observeAccounts loads the account store, records the current modification
+// times of chars and users, and saves it back. Failures only produce a warning
+// since pairings are informational; an empty in-memory store is used instead.
+func observeAccounts(chars []eve.CharacterSettings, users []eve.UserSettings) *eve.AccountStore {
+	store, err := openAccountStore()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		store = eve.NewAccountStore("")
+		store.Observe(chars, users)
+		return store
+	}
+
+	if store.Observe(chars, users) {
+		if err := store.Save(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	return store
+}
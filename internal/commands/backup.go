@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/jpbriend/eve-settings-manager/internal/backup"
-	"github.com/jpbriend/eve-settings-manager/internal/esi"
 	"github.com/jpbriend/eve-settings-manager/internal/eve"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +12,7 @@ import (
 var (
 	backupAll    bool
 	backupOutput string
+	backupUsers  []int64
 )
 
 var backupCmd = &cobra.Command{
@@ -20,64 +20,94 @@ var backupCmd = &cobra.Command{
 	Short: "Backup character settings to a ZIP file",
 	Long: `Create a ZIP backup of character settings.
 
-You can specify a character by ID or name. Use --all to backup all characters.
+You can specify a character by ID or name. Use --all to backup all characters
+and account user files (core_user_*.dat). Use --user to include specific
+account user files by user ID.
 The backup includes metadata with character names and timestamps.`,
 	RunE: runBackup,
 }
 
 func init() {
-	backupCmd.Flags().BoolVar(&backupAll, "all", false, "Backup all characters")
+	backupCmd.Flags().BoolVar(&backupAll, "all", false, "Backup all characters and account user files")
 	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "Output file path")
+	backupCmd.Flags().Int64SliceVar(&backupUsers, "user", nil, "Account user file(s) to backup (user ID)")
 }
 
 func runBackup(cmd *cobra.Command, args []string) error {
 	// Detect settings directories
-	dirs, err := eve.DetectSettingsDirectories()
+	dirs, err := detectSettingsDirectories()
 	if err != nil {
-		return fmt.Errorf("failed to detect settings directories: %w", err)
+		return err
 	}
 
-	if len(dirs) == 0 {
-		return fmt.Errorf("no Eve Online settings directories found")
-	}
-
-	// Find all character settings
+	// Find all character and user settings
 	allCharacters, err := eve.FindCharacterSettings(dirs)
-	if err != nil {
+	if err = reportWarnings(err); err != nil {
 		return fmt.Errorf("failed to find character settings: %w", err)
 	}
 
-	if len(allCharacters) == 0 {
+	allUsers, err := eve.FindUserSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return fmt.Errorf("failed to find user settings: %w", err)
+	}
+
+	if len(allCharacters) == 0 && len(allUsers) == 0 {
 		return fmt.Errorf("no character settings files found")
 	}
 
 	// ESI client for name resolution
-	esiClient := esi.NewClient()
+	esiClient := newESIClient()
 
-	// Determine which characters to backup
+	// Determine which characters and users to backup
 	var charactersToBackup []eve.CharacterSettings
+	var usersToBackup []eve.UserSettings
 
 	if backupAll {
-		charactersToBackup = allCharacters
-	} else if len(args) > 0 {
-		// Resolve character by ID or name
-		charID, err := esiClient.ResolveCharacter(args[0])
-		if err != nil {
-			return fmt.Errorf("failed to resolve character '%s': %w", args[0], err)
+		// A backup holds one file per character; keep the most recent one
+		for _, files := range eve.GroupCharacters(allCharacters) {
+			charactersToBackup = append(charactersToBackup, files[0])
+			if len(files) > 1 {
+				fmt.Printf("Character %d has settings in %d folders; backing up the most recent one:\n  %s\n"+
+					"Use --profile or --server to back up another one.\n",
+					files[0].CharacterID, len(files), files[0].FilePath)
+			}
 		}
+		usersToBackup = allUsers
+	} else if len(args) > 0 || len(backupUsers) > 0 {
+		if len(args) > 0 {
+			// Resolve character by ID or name
+			charID, err := esiClient.ResolveCharacter(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to resolve character '%s': %w", args[0], err)
+			}
 
-		for _, c := range allCharacters {
-			if c.CharacterID == charID {
-				charactersToBackup = append(charactersToBackup, c)
-				break
+			label := fmt.Sprintf("%s (%d)", esiClient.GetCharacterNameOrFallback(cmd.Context(), charID), charID)
+			char, err := chooseCharacterFile(label, eve.CharacterFiles(allCharacters, charID), "--profile or --server")
+			if err != nil {
+				return err
 			}
+			if char == nil {
+				return fmt.Errorf("character '%s' (ID: %d) not found in local settings", args[0], charID)
+			}
+			charactersToBackup = append(charactersToBackup, *char)
 		}
 
-		if len(charactersToBackup) == 0 {
-			return fmt.Errorf("character '%s' (ID: %d) not found in local settings", args[0], charID)
+		for _, userID := range backupUsers {
+			found := false
+			for _, u := range allUsers {
+				if u.UserID == userID {
+					usersToBackup = append(usersToBackup, u)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return fmt.Errorf("user %d not found in local settings", userID)
+			}
 		}
 	} else {
-		return fmt.Errorf("please specify a character (ID or name) or use --all to backup all characters")
+		return fmt.Errorf("please specify a character (ID or name), --user, or use --all to backup all characters")
 	}
 
 	// Fetch character names
@@ -85,7 +115,7 @@ func runBackup(cmd *cobra.Command, args []string) error {
 	for i, c := range charactersToBackup {
 		charIDs[i] = c.CharacterID
 	}
-	names := esiClient.BatchGetCharacterNames(charIDs)
+	names := esiClient.BatchGetCharacterNames(cmd.Context(), charIDs)
 
 	// Prepare backup data
 	backupChars := make([]backup.CharacterBackup, len(charactersToBackup))
@@ -101,6 +131,18 @@ func runBackup(cmd *cobra.Command, args []string) error {
 		files[c.CharacterID] = c.FilePath
 	}
 
+	backupUserFiles := make([]backup.UserBackup, len(usersToBackup))
+	userFiles := make(map[int64]string)
+
+	for i, u := range usersToBackup {
+		backupUserFiles[i] = backup.UserBackup{
+			UserID:       u.UserID,
+			OriginalPath: u.FilePath,
+			FileName:     fmt.Sprintf("core_user_%d.dat", u.UserID),
+		}
+		userFiles[u.UserID] = u.FilePath
+	}
+
 	// Determine output path
 	outputPath := backupOutput
 	if outputPath == "" {
@@ -108,7 +150,7 @@ func runBackup(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create backup
-	if err := backup.CreateBackup(outputPath, backupChars, files); err != nil {
+	if err := backup.CreateBackupWithUsers(outputPath, backupChars, files, backupUserFiles, userFiles); err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
@@ -117,6 +159,12 @@ func runBackup(cmd *cobra.Command, args []string) error {
 	for _, c := range backupChars {
 		fmt.Printf("  - %s (%d)\n", c.CharacterName, c.CharacterID)
 	}
+	if len(backupUserFiles) > 0 {
+		fmt.Printf("User files backed up: %d\n", len(backupUserFiles))
+		for _, u := range backupUserFiles {
+			fmt.Printf("  - core_user_%d.dat\n", u.UserID)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jpbriend/eve-settings-manager/internal/esi"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of character names",
+	Long: `Character names and IDs looked up with ESI are kept in a cache file in your
+user cache directory, so that later runs don't fetch them again. Entries
+expire as ESI tells; an expired name is checked with ESI when next needed, and
+is still used when ESI cannot be reached.`,
+}
+
+var cacheShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the location and contents of the cache",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheShow,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired entries from the cache",
+	Args:  cobra.NoArgs,
+	RunE:  runCachePrune,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cache",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheShowCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func runCacheShow(cmd *cobra.Command, args []string) error {
+	path, err := esi.DefaultCachePath(esiDatasource())
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		fmt.Printf("ESI cache: %s (not created)\n", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read ESI cache: %w", err)
+	}
+
+	cache, err := esi.LoadCache(path)
+	if err != nil {
+		return fmt.Errorf("%w (delete it with 'esm cache clear')", err)
+	}
+
+	now := time.Now()
+	stats := cache.Stats(now)
+	fmt.Printf("ESI cache: %s (%s)\n", path, formatSize(info.Size()))
+	fmt.Printf("Characters:   %d (%d expired)\n", stats.Characters, stats.ExpiredCharacters)
+	fmt.Printf("Name lookups: %d (%d expired)\n", stats.Names, stats.ExpiredNames)
+
+	if !verbose || stats.Characters == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(cache.Characters))
+	for id := range cache.Characters {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CHARACTER ID\tNAME\tEXPIRES")
+	for _, id := range ids {
+		entry := cache.Characters[id]
+		expires := entry.Expires.Local().Format("2006-01-02 15:04:05")
+		if !now.Before(entry.Expires) {
+			expires += " (expired)"
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", id, entry.Info.Name, expires)
+	}
+	_ = w.Flush()
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	path, err := esi.DefaultCachePath(esiDatasource())
+	if err != nil {
+		return err
+	}
+
+	cache, err := esi.LoadCache(path)
+	if err != nil {
+		return fmt.Errorf("%w (delete it with 'esm cache clear')", err)
+	}
+
+	removed := cache.Prune(time.Now())
+	if err := cache.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %d expired entr(ies) from %s\n", removed, path)
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	path, err := esi.DefaultCachePath(esiDatasource())
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("ESI cache is already empty.")
+			return nil
+		}
+		return fmt.Errorf("failed to delete ESI cache: %w", err)
+	}
+
+	fmt.Printf("Deleted %s\n", path)
+	return nil
+}
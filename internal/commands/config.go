@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jpbriend/eve-settings-manager/internal/config"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show or edit the esm configuration file",
+	Long: `Show or edit the esm configuration file.
+
+The configuration file is config.toml in the esm folder of your user
+configuration directory (~/.config/esm on Linux, %AppData%\esm on Windows,
+~/Library/Application Support/esm on macOS). It can list extra settings
+folders (settings_roots), Wine prefixes (wine_prefixes) and folders that are
+never searched (exclude).
+
+Extra settings folders can also be given with ESM_SETTINGS_ROOT (separated
+like PATH entries) or --settings-root.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configuration and the folders searched for settings",
+	RunE:  runConfigShow,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the location of the configuration file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.DefaultPath()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the configuration file in your editor",
+	Long: `Open the configuration file in $VISUAL or $EDITOR, creating it from a
+commented template if it does not exist yet.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEdit,
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configEditCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Config file: %s", path)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Print(" (not created)")
+	}
+	fmt.Println()
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	printPaths("Settings roots", cfg.SettingsRoots)
+	printPaths("Wine prefixes", cfg.WinePrefixes)
+	printPaths("Excluded", cfg.Exclude)
+	printPaths(config.EnvSettingsRoot, config.EnvRoots())
+	printPaths("--settings-root", settingsRoots)
+	printAliases(cfg.Aliases)
+
+	fmt.Println("\nESI:")
+	fmt.Printf("  URL:        %s\n", firstNonEmpty(esiBaseURL, cfg.ESI.BaseURL, "default"))
+	fmt.Printf("  Datasource: %s\n", firstNonEmpty(esiDatasource(), "default"))
+	fmt.Printf("  User-Agent: %s\n", firstNonEmpty(esiUserAgent, cfg.ESI.UserAgent, "default"))
+	if offline {
+		fmt.Println("  Offline:    yes")
+	}
+
+	fmt.Println("\nSearched folders:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, root := range eve.SearchRoots() {
+		status := "missing"
+		if _, err := os.Stat(root.Path); err == nil {
+			status = "found"
+		}
+		_, _ = fmt.Fprintf(w, "  [%s]\t%s\t%s\n", status, root.Launcher, root.Path)
+	}
+	_ = w.Flush()
+	return nil
+}
+
+// printAliases prints the character aliases, or nothing if there are none.
+func printAliases(aliases map[string]int64) {
+	if len(aliases) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("\nAliases:")
+	for _, name := range names {
+		fmt.Printf("  %s = %d\n", name, aliases[name])
+	}
+}
+
+// printPaths prints a labelled list of paths, or nothing if it is empty.
+func printPaths(label string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", label)
+	for _, p := range paths {
+		fmt.Printf("  %s\n", p)
+	}
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(config.Template), 0644); err != nil {
+			return fmt.Errorf("failed to create config file: %w", err)
+		}
+		fmt.Printf("Created %s\n", path)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// The editor may come with arguments, e.g. "code --wait"
+	editorArgs := strings.Fields(editor)
+	c := exec.Command(editorArgs[0], append(editorArgs[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %s: %w", editor, err)
+	}
+
+	// Report mistakes right away rather than on the next command
+	if _, err := config.Load(path); err != nil {
+		return err
+	}
+	return nil
+}
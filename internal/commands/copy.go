@@ -1,23 +1,34 @@
 package commands
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/jpbriend/eve-settings-manager/internal/backup"
-	"github.com/jpbriend/eve-settings-manager/internal/esi"
 	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
 	"github.com/spf13/cobra"
 )
 
 var (
-	copyFrom  string
-	copyTo    string
-	copyForce bool
+	copyFrom     string
+	copyTo       string
+	copyFromUser int64
+	copyToUser   int64
+	copyAccount  bool
+	copyOnly     []string
+	copyExclude  []string
+	copyRescale  string
+	copyMerge    string
+	copyToProf   string
+	copyToInst   string
+	copySource   string
+	copyForce    bool
 )
 
 var copyCmd = &cobra.Command{
@@ -26,140 +37,735 @@ var copyCmd = &cobra.Command{
 	Long: `Copy character settings from one character to another.
 
 Works across different accounts. Automatically creates a backup of the target
-character settings before overwriting.`,
+character settings before overwriting.
+
+Use --from-user and --to-user to copy account-wide settings (core_user_*.dat)
+between accounts. Both pairs can be given to copy character and account
+settings in one operation. Use --with-account to copy the source character's
+account user file onto the target character's account automatically; both
+account files are taken from the folders of the characters' settings files.
+
+Use --only or --exclude to copy selected top-level settings sections (as shown
+by 'esm inspect' or 'esm diff') instead of the whole file. With --only, the
+target keeps everything but the listed sections; with --exclude, the target
+keeps its own copy of the listed sections.
+
+Use --rescale FROM:TO (e.g. 3840x2160:1920x1080) when the target plays at a
+different screen resolution: copied window positions and sizes are scaled
+proportionally and clamped to the target screen.
+
+Use --to-profile and --to-install to write the target into another settings
+profile or installation, e.g. to seed a PvP profile or a Singularity install
+from a Tranquility character. They default to the source's profile and
+installation; a missing profile folder is created.
+
+Use --merge to combine settings instead of overwriting them. With --merge chat,
+the source's chat channels and chat window settings are added to the target,
+keeping all channels and window settings the target already has.
+
+If the source has settings in several folders, pick one with --source-path,
+--profile or --server, or choose it when asked. The target's file next to
+the source is preferred over its files in other folders.`,
 	RunE: runCopy,
 }
 
 func init() {
 	copyCmd.Flags().StringVar(&copyFrom, "from", "", "Source character (ID or name)")
 	copyCmd.Flags().StringVar(&copyTo, "to", "", "Target character (ID or name)")
+	copyCmd.Flags().Int64Var(&copyFromUser, "from-user", 0, "Source account user file (user ID)")
+	copyCmd.Flags().Int64Var(&copyToUser, "to-user", 0, "Target account user file (user ID)")
+	copyCmd.Flags().BoolVar(&copyAccount, "with-account", false, "Also copy the characters' account user files")
+	copyCmd.Flags().StringSliceVar(&copyOnly, "only", nil, "Copy only these settings sections (comma-separated)")
+	copyCmd.Flags().StringSliceVar(&copyExclude, "exclude", nil, "Copy all settings sections except these (comma-separated)")
+	copyCmd.Flags().StringVar(&copyRescale, "rescale", "", "Rescale window layout between resolutions (FROM:TO, e.g. 3840x2160:1920x1080)")
+	copyCmd.Flags().StringVar(&copyToProf, "to-profile", "", "Write the target into this settings profile (e.g. settings_PvP)")
+	copyCmd.Flags().StringVar(&copyToInst, "to-install", "", "Write the target into this installation (folder path, folder name or server)")
+	copyCmd.Flags().StringVar(&copySource, "source-path", "", "Use the source's settings file at this path (file or profile folder)")
+	copyCmd.Flags().StringVar(&copyMerge, "merge", "", "Merge instead of overwriting, using a strategy (chat)")
 	copyCmd.Flags().BoolVarP(&copyForce, "force", "f", false, "Overwrite without confirmation")
-	_ = copyCmd.MarkFlagRequired("from")
-	_ = copyCmd.MarkFlagRequired("to")
+	copyCmd.MarkFlagsRequiredTogether("from", "to")
+	copyCmd.MarkFlagsRequiredTogether("from-user", "to-user")
+	copyCmd.MarkFlagsOneRequired("from", "from-user")
+	copyCmd.MarkFlagsMutuallyExclusive("with-account", "from-user")
+	copyCmd.MarkFlagsMutuallyExclusive("only", "exclude", "merge")
+	copyCmd.MarkFlagsMutuallyExclusive("rescale", "merge")
 }
 
 func runCopy(cmd *cobra.Command, args []string) error {
-	// ESI client for name resolution
-	esiClient := esi.NewClient()
-
-	// Resolve character IDs (supports both ID and name)
-	fromID, err := esiClient.ResolveCharacter(copyFrom)
+	// Detect settings directories
+	dirs, err := detectSettingsDirectories()
 	if err != nil {
-		return fmt.Errorf("failed to resolve source character '%s': %w", copyFrom, err)
+		return err
 	}
 
-	toID, err := esiClient.ResolveCharacter(copyTo)
-	if err != nil {
-		return fmt.Errorf("failed to resolve target character '%s': %w", copyTo, err)
+	var charCopy *characterCopy
+	if copyFrom != "" {
+		charCopy, err = prepareCharacterCopy(cmd.Context(), dirs)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Detect settings directories
-	dirs, err := eve.DetectSettingsDirectories()
+	if len(copyOnly) > 0 || len(copyExclude) > 0 {
+		if charCopy == nil {
+			return fmt.Errorf("--only and --exclude require --from and --to")
+		}
+		if err := charCopy.prepareSections(copyOnly, copyExclude); err != nil {
+			return err
+		}
+	}
+
+	if copyMerge != "" {
+		if charCopy == nil {
+			return fmt.Errorf("--merge requires --from and --to")
+		}
+		if err := charCopy.prepareMerge(copyMerge); err != nil {
+			return err
+		}
+	}
+
+	if copyRescale != "" {
+		if charCopy == nil {
+			return fmt.Errorf("--rescale requires --from and --to")
+		}
+		from, to, err := settings.ParseRescale(copyRescale)
+		if err != nil {
+			return err
+		}
+		if err := charCopy.prepareRescale(from, to); err != nil {
+			return err
+		}
+	}
+
+	if copyAccount {
+		if charCopy == nil {
+			return fmt.Errorf("--with-account requires --from and --to")
+		}
+		copyFromUser, copyToUser, err = resolveCopyAccounts(dirs, charCopy)
+		if err != nil {
+			return err
+		}
+		if copyFromUser == copyToUser && copyToProf == "" && copyToInst == "" {
+			fmt.Printf("Both characters belong to user %d; account settings are already shared.\n", copyFromUser)
+			copyFromUser, copyToUser = 0, 0
+		}
+	}
+
+	var userCopy *userSettingsCopy
+	if copyFromUser != 0 {
+		if copyAccount {
+			userCopy, err = prepareAccountCopy(charCopy, copyFromUser, copyToUser)
+		} else {
+			userCopy, err = prepareUserCopy(dirs, copyFromUser, copyToUser)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if charCopy != nil && charCopy.merge != "" && len(charCopy.added) == 0 {
+		fmt.Printf("%s (%d) already has all %s settings of %s (%d); nothing to merge.\n",
+			charCopy.targetName, charCopy.targetID, charCopy.merge, charCopy.sourceName, charCopy.source.CharacterID)
+		charCopy = nil
+		if userCopy == nil {
+			return nil
+		}
+	}
+
+	if charCopy != nil && charCopy.target == nil {
+		fmt.Printf("Target character settings file will be created at:\n  %s\n", charCopy.targetPath)
+	}
+	if userCopy != nil && userCopy.target == nil {
+		fmt.Printf("Target user settings file will be created at:\n  %s\n", userCopy.targetPath)
+	}
+
+	// Confirmation prompt
+	if !copyForce {
+		fmt.Printf("\nAbout to copy settings:\n")
+		if charCopy != nil {
+			fmt.Printf("  From: %s (%d)\n", charCopy.sourceName, charCopy.source.CharacterID)
+			fmt.Printf("  To:   %s (%d)\n", charCopy.targetName, charCopy.targetID)
+			if len(charCopy.sections) > 0 {
+				fmt.Printf("  Sections: %s\n", strings.Join(charCopy.sections, ", "))
+			}
+			if charCopy.rescale != "" {
+				fmt.Printf("  Rescale:  %s\n", charCopy.rescale)
+			}
+			if charCopy.merge != "" {
+				fmt.Printf("  Merge:    %s (%d addition(s))\n", charCopy.merge, len(charCopy.added))
+			}
+		}
+		if userCopy != nil {
+			fmt.Printf("  From user: %d\n", userCopy.source.UserID)
+			fmt.Printf("  To user:   %d\n", userCopy.targetID)
+		}
+		if charCopy != nil && charCopy.target != nil {
+			fmt.Printf("\nWARNING: This will overwrite existing settings for %s\n", charCopy.targetName)
+		}
+		if userCopy != nil && userCopy.target != nil {
+			fmt.Printf("\nWARNING: This will overwrite existing account settings for user %d\n", userCopy.targetID)
+		}
+		ok, err := confirm("Proceed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	if charCopy != nil {
+		if err := charCopy.run(); err != nil {
+			return err
+		}
+	}
+
+	if userCopy != nil {
+		if err := userCopy.run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// characterCopy describes a pending copy of one character's settings.
+type characterCopy struct {
+	source     *eve.CharacterSettings
+	target     *eve.CharacterSettings // nil if the target has no local settings yet
+	targetID   int64
+	targetPath string
+	sourceName string
+	targetName string
+
+	// Set when only some sections are copied, the layout is rescaled or the
+	// settings are merged
+	merged   *settings.File
+	sections []string
+	rescale  string
+	merge    string
+	added    []settings.Addition
+}
+
+// prepareCharacterCopy resolves the --from and --to characters.
+func prepareCharacterCopy(ctx context.Context, dirs []string) (*characterCopy, error) {
+	// ESI client for name resolution
+	esiClient := newESIClient()
+
+	// Resolve character IDs (supports both ID and name)
+	fromID, err := esiClient.ResolveCharacter(ctx, copyFrom)
 	if err != nil {
-		return fmt.Errorf("failed to detect settings directories: %w", err)
+		return nil, fmt.Errorf("failed to resolve source character '%s': %w", copyFrom, err)
 	}
 
-	if len(dirs) == 0 {
-		return fmt.Errorf("no Eve Online settings directories found")
+	toID, err := esiClient.ResolveCharacter(ctx, copyTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve target character '%s': %w", copyTo, err)
 	}
 
 	// Find all character settings
 	allCharacters, err := eve.FindCharacterSettings(dirs)
-	if err != nil {
-		return fmt.Errorf("failed to find character settings: %w", err)
+	if err = reportWarnings(err); err != nil {
+		return nil, fmt.Errorf("failed to find character settings: %w", err)
 	}
 
+	sourceName := esiClient.GetCharacterNameOrFallback(ctx, fromID)
+	targetName := esiClient.GetCharacterNameOrFallback(ctx, toID)
+
 	// Find source character
-	var sourceChar *eve.CharacterSettings
-	for _, c := range allCharacters {
-		if c.CharacterID == fromID {
-			sourceChar = &c
-			break
+	sourceFiles := eve.CharacterFiles(allCharacters, fromID)
+	if copySource != "" {
+		sourceFiles, err = filterSourcePath(sourceFiles, copySource)
+		if err != nil {
+			return nil, err
 		}
 	}
-
+	sourceChar, err := chooseCharacterFile(fmt.Sprintf("Source %s (%d)", sourceName, fromID), sourceFiles,
+		"--source-path, --profile or --server")
+	if err != nil {
+		return nil, err
+	}
 	if sourceChar == nil {
-		return fmt.Errorf("source character %d not found in local settings", fromID)
+		if copySource != "" {
+			return nil, fmt.Errorf("source character %d has no settings in %s", fromID, copySource)
+		}
+		return nil, fmt.Errorf("source character %d not found in local settings", fromID)
+	}
+
+	targetDir, err := resolveCopyTargetDir(sourceChar.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if targetDir != "" {
+		// Only a settings file in the requested profile counts as the target;
+		// a profile that does not exist yet is created with it
+		allCharacters = nil
+		if _, err := os.Stat(targetDir); !os.IsNotExist(err) {
+			allCharacters, err = eve.FindCharacterSettings([]string{targetDir})
+			if err = reportWarnings(err); err != nil {
+				return nil, fmt.Errorf("failed to find character settings: %w", err)
+			}
+		}
 	}
 
-	// Find or prepare target character
+	// Find or prepare target character, preferring its file next to the source
+	targetFiles := eve.CharacterFiles(allCharacters, toID)
 	var targetChar *eve.CharacterSettings
-	for _, c := range allCharacters {
-		if c.CharacterID == toID {
+	for _, c := range targetFiles {
+		if filepath.Dir(c.FilePath) == filepath.Dir(sourceChar.FilePath) {
 			targetChar = &c
 			break
 		}
 	}
+	if targetChar == nil {
+		targetChar, err = chooseCharacterFile(fmt.Sprintf("Target %s (%d)", targetName, toID), targetFiles,
+			"--profile, --server, --to-profile or --to-install")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if targetChar != nil && targetChar.FilePath == sourceChar.FilePath {
+		return nil, fmt.Errorf("source and target are the same settings file: %s", sourceChar.FilePath)
+	}
 
-	// Get character names for display
-	sourceName := esiClient.GetCharacterNameOrFallback(fromID)
-	targetName := esiClient.GetCharacterNameOrFallback(toID)
+	cc := &characterCopy{
+		source:     sourceChar,
+		target:     targetChar,
+		targetID:   toID,
+		sourceName: sourceName,
+		targetName: targetName,
+	}
 
 	// If target doesn't exist locally, we need to create it
-	var targetPath string
 	if targetChar == nil {
-		// Use same settings directory as source
-		targetPath = eve.CreateCharacterSettingsPath(sourceChar, toID)
-		fmt.Printf("Target character settings file will be created at:\n  %s\n", targetPath)
+		if targetDir != "" {
+			cc.targetPath = eve.CreateCharacterSettingsPathIn(targetDir, toID)
+		} else {
+			// Use same settings directory as source
+			cc.targetPath = eve.CreateCharacterSettingsPath(sourceChar, toID)
+		}
 	} else {
-		targetPath = targetChar.FilePath
+		cc.targetPath = targetChar.FilePath
 	}
 
-	// Confirmation prompt
-	if !copyForce {
-		fmt.Printf("\nAbout to copy settings:\n")
-		fmt.Printf("  From: %s (%d)\n", sourceName, fromID)
-		fmt.Printf("  To:   %s (%d)\n", targetName, toID)
-		if targetChar != nil {
-			fmt.Printf("\nWARNING: This will overwrite existing settings for %s\n", targetName)
+	return cc, nil
+}
+
+// filterSourcePath returns the files among files that are, or lie directly in,
+// path.
+func filterSourcePath(files []eve.CharacterSettings, path string) ([]eve.CharacterSettings, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []eve.CharacterSettings
+	for _, f := range files {
+		if f.FilePath == abs || filepath.Dir(f.FilePath) == abs {
+			filtered = append(filtered, f)
 		}
-		fmt.Print("\nProceed? [y/N]: ")
+	}
+	return filtered, nil
+}
 
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("Operation cancelled.")
-			return nil
+// prepareSections decodes both settings files and builds the target settings
+// with the selected sections transplanted from the source.
+func (cc *characterCopy) prepareSections(only, exclude []string) error {
+	if cc.target == nil {
+		return fmt.Errorf("%s (%d) has no local settings to copy sections into", cc.targetName, cc.targetID)
+	}
+
+	source, err := settings.Load(cc.source.FilePath)
+	if err != nil {
+		return err
+	}
+	target, err := settings.Load(cc.targetPath)
+	if err != nil {
+		return err
+	}
+
+	copied, err := settings.Transplant(source, target, only, exclude)
+	if err != nil {
+		return err
+	}
+
+	cc.merged = target
+	cc.sections = copied
+	return nil
+}
+
+// prepareMerge decodes both settings files and builds the target settings
+// with the source merged in using the named strategy.
+func (cc *characterCopy) prepareMerge(name string) error {
+	strategy, err := settings.LookupMergeStrategy(name)
+	if err != nil {
+		return err
+	}
+	if cc.target == nil {
+		return fmt.Errorf("%s (%d) has no local settings to merge into", cc.targetName, cc.targetID)
+	}
+
+	source, err := settings.Load(cc.source.FilePath)
+	if err != nil {
+		return err
+	}
+	target, err := settings.Load(cc.targetPath)
+	if err != nil {
+		return err
+	}
+
+	added, err := strategy(source, target)
+	if err != nil {
+		return err
+	}
+
+	cc.merged = target
+	cc.merge = name
+	cc.added = added
+	return nil
+}
+
+// prepareRescale decodes the settings to copy, if not done yet, and rescales
+// their window layout between the given resolutions.
+func (cc *characterCopy) prepareRescale(from, to settings.Resolution) error {
+	if cc.merged == nil {
+		source, err := settings.Load(cc.source.FilePath)
+		if err != nil {
+			return err
 		}
+		cc.merged = source
+	} else if !slices.Contains(cc.sections, settings.WindowsSection) {
+		fmt.Printf("The %s section is not copied; --rescale has no effect.\n", settings.WindowsSection)
+		return nil
 	}
 
-	// Create backup of target if it exists
-	if targetChar != nil {
-		backupDir := filepath.Dir(targetChar.FilePath)
-		backupPath := filepath.Join(backupDir, fmt.Sprintf("core_char_%d_%s.dat.bak",
-			toID, time.Now().Format("20060102_150405")))
-
-		charBackup := []backup.CharacterBackup{{
-			CharacterID:   toID,
-			CharacterName: targetName,
-			OriginalPath:  targetChar.FilePath,
-			FileName:      fmt.Sprintf("core_char_%d.dat", toID),
-		}}
-		files := map[int64]string{toID: targetChar.FilePath}
+	n, err := settings.RescaleWindows(cc.merged, from, to)
+	if err != nil {
+		return err
+	}
 
-		zipBackupPath := filepath.Join(backupDir, fmt.Sprintf("backup_%d_%s.zip",
-			toID, time.Now().Format("20060102_150405")))
+	cc.rescale = fmt.Sprintf("%s -> %s (%d window(s))", from, to, n)
+	return nil
+}
 
-		if err := backup.CreateBackup(zipBackupPath, charBackup, files); err != nil {
+// run backs up the target character and copies the source settings over it.
+func (cc *characterCopy) run() error {
+	// Create backup of target if it exists
+	if cc.target != nil {
+		zipBackupPath, err := backupCharacterFile(cc.targetID, cc.targetName, cc.target.FilePath)
+		if err != nil {
 			return fmt.Errorf("failed to create backup of target: %w", err)
 		}
-		fmt.Printf("Backup created: %s\n", backupPath)
+		fmt.Printf("Backup created: %s\n", zipBackupPath)
 	}
 
 	// Perform the copy
-	targetSettings := &eve.CharacterSettings{
-		CharacterID: toID,
-		FilePath:    targetPath,
-	}
+	if cc.merged != nil {
+		if err := os.MkdirAll(filepath.Dir(cc.targetPath), 0755); err != nil {
+			return fmt.Errorf("failed to create settings directory: %w", err)
+		}
+		if err := cc.merged.Save(cc.targetPath); err != nil {
+			return fmt.Errorf("failed to copy settings sections: %w", err)
+		}
+	} else {
+		targetSettings := &eve.CharacterSettings{
+			CharacterID: cc.targetID,
+			FilePath:    cc.targetPath,
+		}
 
-	if err := eve.CopySettings(sourceChar, targetSettings, ""); err != nil {
-		return fmt.Errorf("failed to copy settings: %w", err)
+		if err := eve.CopySettings(cc.source, targetSettings, ""); err != nil {
+			return fmt.Errorf("failed to copy settings: %w", err)
+		}
 	}
 
 	fmt.Printf("\nSettings copied successfully!\n")
-	fmt.Printf("  From: %s (%d)\n", sourceName, fromID)
-	fmt.Printf("  To:   %s (%d)\n", targetName, toID)
+	fmt.Printf("  From: %s (%d)\n", cc.sourceName, cc.source.CharacterID)
+	fmt.Printf("  To:   %s (%d)\n", cc.targetName, cc.targetID)
+	if len(cc.sections) > 0 {
+		fmt.Printf("  Sections: %s\n", strings.Join(cc.sections, ", "))
+	}
+	if cc.rescale != "" {
+		fmt.Printf("  Rescale:  %s\n", cc.rescale)
+	}
+	if cc.merge != "" {
+		fmt.Printf("  Merged:   %s\n", cc.merge)
+		for _, a := range cc.added {
+			fmt.Printf("    + %s %s\n", a.Kind, a.Name)
+		}
+	}
+
+	return nil
+}
+
+// resolveCopyAccounts returns the account user IDs owning the source and
+// target characters of cc.
+func resolveCopyAccounts(dirs []string, cc *characterCopy) (int64, int64, error) {
+	chars, err := eve.FindCharacterSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return 0, 0, fmt.Errorf("failed to find character settings: %w", err)
+	}
+	users, err := eve.FindUserSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return 0, 0, fmt.Errorf("failed to find user settings: %w", err)
+	}
+	accounts := observeAccounts(chars, users)
+
+	sourceLink, ok := accounts.Resolve(cc.source.CharacterID)
+	if !ok {
+		return 0, 0, fmt.Errorf("cannot determine the account of %s (%d); pair it with 'esm account link'",
+			cc.sourceName, cc.source.CharacterID)
+	}
+	targetLink, ok := accounts.Resolve(cc.targetID)
+	if !ok {
+		return 0, 0, fmt.Errorf("cannot determine the account of %s (%d); pair it with 'esm account link'",
+			cc.targetName, cc.targetID)
+	}
+
+	return sourceLink.UserID, targetLink.UserID, nil
+}
+
+// resolveCopyTargetDir returns the settings directory selected with
+// --to-profile and --to-install for copying the settings file at sourcePath,
+// or "" if neither is set. Each defaults to the source's own.
+func resolveCopyTargetDir(sourcePath string) (string, error) {
+	if copyToProf == "" && copyToInst == "" {
+		return "", nil
+	}
+
+	sourceDir := filepath.Dir(sourcePath)
+	installPath := filepath.Dir(sourceDir)
+	profile := eve.ProfileName(sourceDir)
+
+	if copyToInst != "" {
+		var err error
+		installPath, err = findInstallationPath(copyToInst)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if copyToProf != "" {
+		profile = strings.TrimPrefix(copyToProf, eve.ProfilePrefix)
+		if err := eve.ValidateProfileName(profile); err != nil {
+			return "", err
+		}
+	}
+
+	return filepath.Join(installPath, eve.ProfilePrefix+profile), nil
+}
+
+// findInstallationPath returns the folder of the installation given by its
+// folder path, folder name or server. Installations of every server are
+// considered, regardless of --server.
+func findInstallationPath(identifier string) (string, error) {
+	installs, err := eve.DetectInstallations()
+	if err = reportWarnings(err); err != nil {
+		return "", fmt.Errorf("failed to detect settings directories: %w", err)
+	}
+
+	for _, install := range installs {
+		if install.Name == identifier || install.Path == filepath.Clean(identifier) {
+			return install.Path, nil
+		}
+	}
+
+	if server, err := eve.ParseServer(identifier); err == nil {
+		matches := eve.FilterInstallations(installs, server)
+		if len(matches) == 1 {
+			return matches[0].Path, nil
+		}
+		if len(matches) > 1 {
+			return "", fmt.Errorf("several %s installations found; give the installation folder instead", server)
+		}
+	}
+
+	if info, err := os.Stat(identifier); err == nil && info.IsDir() {
+		return filepath.Abs(identifier)
+	}
+
+	return "", fmt.Errorf("installation '%s' not found", identifier)
+}
+
+// backupCharacterFile creates a ZIP backup of a character's settings file next
+// to it and returns the backup path.
+func backupCharacterFile(charID int64, name, path string) (string, error) {
+	zipBackupPath := filepath.Join(filepath.Dir(path), fmt.Sprintf("backup_%d_%s.zip",
+		charID, time.Now().Format("20060102_150405")))
+
+	charBackup := []backup.CharacterBackup{{
+		CharacterID:   charID,
+		CharacterName: name,
+		OriginalPath:  path,
+		FileName:      fmt.Sprintf("core_char_%d.dat", charID),
+	}}
+	files := map[int64]string{charID: path}
+
+	if err := backup.CreateBackup(zipBackupPath, charBackup, files); err != nil {
+		return "", err
+	}
+	return zipBackupPath, nil
+}
+
+// userSettingsCopy describes a pending copy of one account user file.
+type userSettingsCopy struct {
+	source     *eve.UserSettings
+	target     *eve.UserSettings // nil if the target has no local settings yet
+	targetID   int64
+	targetPath string
+}
+
+// prepareUserCopy locates the source and target account user files.
+func prepareUserCopy(dirs []string, fromID, toID int64) (*userSettingsCopy, error) {
+	allUsers, err := eve.FindUserSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return nil, fmt.Errorf("failed to find user settings: %w", err)
+	}
+
+	uc := &userSettingsCopy{targetID: toID}
+	uc.source, err = chooseUserFile(fmt.Sprintf("Source user %d", fromID), eve.UserFiles(allUsers, fromID),
+		"--profile or --server")
+	if err != nil {
+		return nil, err
+	}
+	if uc.source == nil {
+		return nil, fmt.Errorf("source user %d not found in local settings", fromID)
+	}
+
+	targetDir, err := resolveCopyTargetDir(uc.source.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if targetDir != "" {
+		// Only a user file in the requested profile counts as the target
+		uc.target, err = findUserFile(targetDir, toID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Prefer the target's file next to the source
+		targetFiles := eve.UserFiles(allUsers, toID)
+		for _, u := range targetFiles {
+			if filepath.Dir(u.FilePath) == filepath.Dir(uc.source.FilePath) {
+				uc.target = &u
+				break
+			}
+		}
+		if uc.target == nil {
+			uc.target, err = chooseUserFile(fmt.Sprintf("Target user %d", toID), targetFiles,
+				"--profile, --server, --to-profile or --to-install")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if uc.target != nil && uc.target.FilePath == uc.source.FilePath {
+		return nil, fmt.Errorf("source and target are the same settings file: %s", uc.source.FilePath)
+	}
+
+	if uc.target == nil {
+		settingsDir := targetDir
+		if settingsDir == "" {
+			// Use same settings directory as source
+			settingsDir = uc.source.GetSettingsDir()
+		}
+		uc.targetPath = eve.CreateUserSettingsPath(settingsDir, toID)
+	} else {
+		uc.targetPath = uc.target.FilePath
+	}
+
+	return uc, nil
+}
+
+// prepareAccountCopy locates the account user files of the characters of cc
+// for --with-account: the source one in the source character's folder and the
+// target one in the target character's folder, so that no other installation's
+// or server's account file is read or overwritten. The target file is only
+// created if the target character's file is created in that folder too.
+func prepareAccountCopy(cc *characterCopy, fromID, toID int64) (*userSettingsCopy, error) {
+	sourceDir := cc.source.GetSettingsDir()
+	targetDir := filepath.Dir(cc.targetPath)
+
+	source, err := findUserFile(sourceDir, fromID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("account file of %s (user %d) not found in %s", cc.sourceName, fromID, sourceDir)
+	}
+
+	target, err := findUserFile(targetDir, toID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil && cc.target != nil {
+		return nil, fmt.Errorf("account file of %s (user %d) not found in %s", cc.targetName, toID, targetDir)
+	}
+	if target != nil && target.FilePath == source.FilePath {
+		return nil, fmt.Errorf("source and target are the same settings file: %s", source.FilePath)
+	}
+
+	uc := &userSettingsCopy{source: source, target: target, targetID: toID}
+	if target == nil {
+		uc.targetPath = eve.CreateUserSettingsPath(targetDir, toID)
+	} else {
+		uc.targetPath = target.FilePath
+	}
+	return uc, nil
+}
+
+// findUserFile returns the account user file of userID in the settings
+// directory dir, or nil if there is none.
+func findUserFile(dir string, userID int64) (*eve.UserSettings, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	users, err := eve.FindUserSettings([]string{dir})
+	if err = reportWarnings(err); err != nil {
+		return nil, fmt.Errorf("failed to find user settings: %w", err)
+	}
+	for _, u := range users {
+		if u.UserID == userID {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+// run backs up the target user file and copies the source settings over it.
+func (uc *userSettingsCopy) run() error {
+	// Create backup of target if it exists
+	if uc.target != nil {
+		backupDir := filepath.Dir(uc.target.FilePath)
+		zipBackupPath := filepath.Join(backupDir, fmt.Sprintf("backup_user_%d_%s.zip",
+			uc.targetID, time.Now().Format("20060102_150405")))
+
+		userBackup := []backup.UserBackup{{
+			UserID:       uc.targetID,
+			OriginalPath: uc.target.FilePath,
+			FileName:     fmt.Sprintf("core_user_%d.dat", uc.targetID),
+		}}
+		files := map[int64]string{uc.targetID: uc.target.FilePath}
+
+		if err := backup.CreateBackupWithUsers(zipBackupPath, nil, nil, userBackup, files); err != nil {
+			return fmt.Errorf("failed to create backup of target user: %w", err)
+		}
+		fmt.Printf("Backup created: %s\n", zipBackupPath)
+	}
+
+	targetSettings := &eve.UserSettings{
+		UserID:   uc.targetID,
+		FilePath: uc.targetPath,
+	}
+
+	if err := eve.CopyUserSettings(uc.source, targetSettings, ""); err != nil {
+		return fmt.Errorf("failed to copy user settings: %w", err)
+	}
+
+	fmt.Printf("\nAccount settings copied successfully!\n")
+	fmt.Printf("  From user: %d\n", uc.source.UserID)
+	fmt.Printf("  To user:   %d\n", uc.targetID)
 
 	return nil
 }
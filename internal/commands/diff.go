@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jpbriend/eve-settings-manager/internal/esi"
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+// maxDiffValueLen is the longest value representation printed by diff.
+const maxDiffValueLen = 60
+
+var (
+	diffFrom string
+	diffTo   string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how two characters' settings differ",
+	Long: `Compare the decoded settings of two characters, grouped by section.
+
+The output shows what copying --from onto --to would change on the target:
+  + settings the target would gain
+  - settings the target would lose
+  ~ settings whose value would change
+
+Characters can be given by ID or name, or as paths to settings files.`,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFrom, "from", "", "Source character (ID, name or path)")
+	diffCmd.Flags().StringVar(&diffTo, "to", "", "Target character (ID, name or path)")
+	_ = diffCmd.MarkFlagRequired("from")
+	_ = diffCmd.MarkFlagRequired("to")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	esiClient := newESIClient()
+
+	fromFile, fromLabel, err := loadCharacterSettings(cmd.Context(), esiClient, diffFrom)
+	if err != nil {
+		return err
+	}
+	toFile, toLabel, err := loadCharacterSettings(cmd.Context(), esiClient, diffTo)
+	if err != nil {
+		return err
+	}
+
+	changes, err := settings.Diff(toFile, fromFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Comparing settings:\n")
+	fmt.Printf("  From: %s\n", fromLabel)
+	fmt.Printf("  To:   %s\n", toLabel)
+
+	if len(changes) == 0 {
+		fmt.Println("\nNo differences found.")
+		return nil
+	}
+
+	sections := 0
+	current := ""
+	for i, c := range changes {
+		if i == 0 || c.Section != current {
+			current = c.Section
+			sections++
+			fmt.Printf("\n[%s]\n", current)
+		}
+
+		path := c.Path
+		if path == "" {
+			path = "(entire section)"
+		}
+
+		switch c.Kind {
+		case settings.Added:
+			fmt.Printf("  + %s = %s\n", path, formatDiffValue(c.New))
+		case settings.Removed:
+			fmt.Printf("  - %s = %s\n", path, formatDiffValue(c.Old))
+		case settings.Changed:
+			fmt.Printf("  ~ %s: %s -> %s\n", path, formatDiffValue(c.Old), formatDiffValue(c.New))
+		}
+	}
+
+	fmt.Printf("\n%d change(s) in %d section(s)\n", len(changes), sections)
+	return nil
+}
+
+// loadCharacterSettings decodes the settings of a character (ID, name or
+// path) and returns it with a label for display.
+func loadCharacterSettings(ctx context.Context, esiClient *esi.Client, identifier string) (*settings.File, string, error) {
+	if info, err := os.Stat(identifier); err == nil && !info.IsDir() {
+		file, err := settings.Load(identifier)
+		return file, identifier, err
+	}
+
+	char, err := findLocalCharacter(ctx, esiClient, identifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := settings.Load(char.FilePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	label := fmt.Sprintf("%s (%d)", esiClient.GetCharacterNameOrFallback(ctx, char.CharacterID), char.CharacterID)
+	return file, label, nil
+}
+
+// formatDiffValue renders a settings value as compact JSON, truncated to
+// maxDiffValueLen characters.
+func formatDiffValue(v marshal.Value) string {
+	data, err := json.Marshal(settings.Plain(settings.EntryValue(v)))
+	if err != nil {
+		return "?"
+	}
+
+	text := []rune(string(data))
+	if len(text) > maxDiffValueLen {
+		return string(text[:maxDiffValueLen-3]) + "..."
+	}
+	return string(text)
+}
@@ -0,0 +1,356 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jpbriend/eve-settings-manager/internal/config"
+	"github.com/jpbriend/eve-settings-manager/internal/esi"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems finding or changing settings",
+	Long: `Check that esm can find and change your Eve settings.
+
+Doctor checks every folder searched for settings, reports folders that
+cannot be read or written, looks for old backups left behind by copy,
+checks that ESI can be reached for character names and that the name cache,
+the configuration and account store load, and whether the Eve client is
+running. For every problem it suggests a fix. Doctor never changes anything.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+// checkStatus is the outcome of a doctor check.
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+func (s checkStatus) String() string {
+	switch s {
+	case checkWarn:
+		return "warn"
+	case checkFail:
+		return "FAIL"
+	}
+	return "ok"
+}
+
+// checkResult is the result of one doctor check.
+type checkResult struct {
+	status  checkStatus
+	message string
+	details []string // shown with --verbose
+	fix     string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	var results []checkResult
+
+	results = append(results, checkConfigFile())
+	installs, rootResults := checkSearchRoots()
+	results = append(results, rootResults...)
+	results = append(results, checkProfilesWritable(installs)...)
+	results = append(results, checkBackupClutter(installs))
+	results = append(results, checkAccountStore())
+	results = append(results, checkESI(cmd.Context()))
+	results = append(results, checkESICache())
+	results = append(results, checkClientRunning())
+
+	var warnings, failures int
+	for _, r := range results {
+		fmt.Printf("[%-4s] %s\n", r.status, r.message)
+		if verbose {
+			for _, d := range r.details {
+				fmt.Printf("         %s\n", d)
+			}
+		}
+		if r.fix != "" {
+			fmt.Printf("         Fix: %s\n", r.fix)
+		}
+
+		switch r.status {
+		case checkWarn:
+			warnings++
+		case checkFail:
+			failures++
+		}
+	}
+
+	fmt.Println()
+	if warnings == 0 && failures == 0 {
+		fmt.Println("Everything looks good.")
+	} else {
+		fmt.Printf("%d problem(s), %d warning(s)\n", failures, warnings)
+	}
+	return nil
+}
+
+func checkConfigFile() checkResult {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return checkResult{status: checkWarn, message: fmt.Sprintf("Config file: %v", err)}
+	}
+
+	if _, err := config.Load(path); err != nil {
+		return checkResult{
+			status:  checkFail,
+			message: fmt.Sprintf("Config file: %v", err),
+			fix:     "Correct the file with 'esm config edit'",
+		}
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return checkResult{message: fmt.Sprintf("Config file: not created (%s)", path)}
+	}
+	return checkResult{message: fmt.Sprintf("Config file: %s", path)}
+}
+
+// checkSearchRoots reads every folder searched for settings and the
+// installations and profiles in them, reporting what cannot be read.
+func checkSearchRoots() ([]eve.Installation, []checkResult) {
+	var details []string
+	found := 0
+
+	roots := eve.SearchRoots()
+	for _, root := range roots {
+		if _, err := os.Stat(root.Path); err != nil {
+			details = append(details, fmt.Sprintf("missing  %s (%s)", root.Path, root.Launcher))
+			continue
+		}
+		found++
+		details = append(details, fmt.Sprintf("found    %s (%s)", root.Path, root.Launcher))
+	}
+
+	installs, err := eve.DetectInstallations()
+	if err != nil && !eve.IsWarnings(err) {
+		return nil, []checkResult{{status: checkFail, message: err.Error()}}
+	}
+	dirs := eve.SettingsDirectories(installs)
+	_, charErr := eve.FindCharacterSettings(dirs)
+	_, userErr := eve.FindUserSettings(dirs)
+
+	summary := checkResult{
+		message: fmt.Sprintf("Settings folders: %d of %d searched location(s) exist, %d installation(s) found",
+			found, len(roots), len(installs)),
+		details: details,
+	}
+	if len(installs) == 0 {
+		summary.status = checkFail
+		summary.fix = "Start Eve once with each character, or add the folder containing your " +
+			"c_* installation folders with 'esm config edit' or --settings-root"
+	}
+
+	results := []checkResult{summary}
+	seen := make(map[string]bool)
+	for _, e := range []error{err, charErr, userErr} {
+		var warnings eve.Warnings
+		if !errors.As(e, &warnings) {
+			continue
+		}
+		for _, w := range warnings {
+			if !seen[w.Path] {
+				seen[w.Path] = true
+				results = append(results, unreadableResult(w.Path, w.Err))
+			}
+		}
+	}
+	return installs, results
+}
+
+// unreadableResult reports a settings folder or file that cannot be searched.
+func unreadableResult(path string, err error) checkResult {
+	result := checkResult{
+		status:  checkFail,
+		message: fmt.Sprintf("Cannot search %s: %v", path, err),
+		fix:     "Check that it is an Eve settings folder or file, or exclude it with 'esm config edit'",
+	}
+	if errors.Is(err, os.ErrPermission) {
+		result.fix = fmt.Sprintf("Run esm as the user who plays Eve, or give your user access to it (e.g. chmod -R u+rwX %q)", path)
+	}
+	return result
+}
+
+// checkProfilesWritable reports settings profiles that esm cannot write to.
+func checkProfilesWritable(installs []eve.Installation) []checkResult {
+	var results []checkResult
+	dirs := eve.SettingsDirectories(installs)
+
+	for _, dir := range dirs {
+		if err := checkWritable(dir); err != nil {
+			results = append(results, checkResult{
+				status:  checkFail,
+				message: fmt.Sprintf("Cannot write to %s: %v", dir, err),
+				fix:     "copy, restore and import need write access; fix the folder's permissions or run esm as the user who plays Eve",
+			})
+		}
+	}
+
+	if len(results) == 0 && len(dirs) > 0 {
+		results = append(results, checkResult{message: fmt.Sprintf("Write access: all %d profile(s) writable", len(dirs))})
+	}
+	return results
+}
+
+// checkWritable creates and removes a file in dir to check that it can be
+// written to.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".esm-doctor-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkBackupClutter looks for the .bak files and backup ZIPs copy, import and
+// profile delete leave next to the settings.
+func checkBackupClutter(installs []eve.Installation) checkResult {
+	var details []string
+	var size int64
+
+	var dirs []string
+	for _, install := range installs {
+		dirs = append(dirs, install.Path)
+		dirs = append(dirs, install.Profiles...)
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			isBackup := strings.HasSuffix(name, ".bak") ||
+				(strings.HasPrefix(name, "backup_") && strings.HasSuffix(name, ".zip"))
+			if entry.IsDir() || !isBackup {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+			details = append(details, filepath.Join(dir, name))
+		}
+	}
+
+	if len(details) == 0 {
+		return checkResult{message: "Backups: no old backups next to the settings"}
+	}
+	return checkResult{
+		status:  checkWarn,
+		message: fmt.Sprintf("Backups: %d old backup file(s) next to the settings (%s)", len(details), formatSize(size)),
+		details: details,
+		fix: "Delete the ones you no longer need (list them with 'esm doctor -v'); " +
+			"any backup ZIP can still be used with 'esm restore' after moving it elsewhere",
+	}
+}
+
+func checkAccountStore() checkResult {
+	path, err := eve.DefaultAccountStorePath()
+	if err != nil {
+		return checkResult{status: checkWarn, message: fmt.Sprintf("Account store: %v", err)}
+	}
+
+	store, err := eve.LoadAccountStore(path)
+	if err != nil {
+		return checkResult{
+			status:  checkFail,
+			message: fmt.Sprintf("Account store: %v", err),
+			fix:     fmt.Sprintf("Delete %s; links made with 'esm account link' have to be made again", path),
+		}
+	}
+	return checkResult{message: fmt.Sprintf("Account store: %d linked character(s) (%s)", len(store.Links), path)}
+}
+
+func checkESI(ctx context.Context) checkResult {
+	if offline {
+		return checkResult{message: "ESI: not used in offline mode"}
+	}
+
+	status, err := esi.NewClient(esiClientOptions()...).GetStatus(ctx)
+	if err != nil {
+		return checkResult{
+			status:  checkWarn,
+			message: fmt.Sprintf("ESI: %v", err),
+			fix: "Check your internet connection and proxy; without ESI only characters in the name cache, " +
+				"backups and aliases of the configuration file are known by name (or use --offline)",
+		}
+	}
+	server := firstNonEmpty(esiDatasource(), esi.DatasourceTranquility)
+	return checkResult{message: fmt.Sprintf("ESI: reachable, %s has %d player(s) online",
+		strings.ToUpper(server[:1])+server[1:], status.Players)}
+}
+
+func checkESICache() checkResult {
+	path, err := esi.DefaultCachePath(esiDatasource())
+	if err != nil {
+		return checkResult{status: checkWarn, message: fmt.Sprintf("ESI cache: %v", err)}
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return checkResult{message: fmt.Sprintf("ESI cache: empty (%s)", path)}
+	}
+
+	cache, err := esi.LoadCache(path)
+	if err != nil {
+		return checkResult{
+			status:  checkFail,
+			message: fmt.Sprintf("ESI cache: %v", err),
+			fix:     "Run 'esm cache clear'; names are fetched from ESI again when needed",
+		}
+	}
+
+	if err := checkWritable(filepath.Dir(path)); err != nil {
+		return checkResult{
+			status:  checkWarn,
+			message: fmt.Sprintf("ESI cache: cannot be updated: %v", err),
+			fix:     fmt.Sprintf("Make %s writable for your user", filepath.Dir(path)),
+		}
+	}
+
+	stats := cache.Stats(time.Now())
+	return checkResult{
+		message: fmt.Sprintf("ESI cache: %d character(s), %d expired (%s, %s)",
+			stats.Characters, stats.ExpiredCharacters, formatSize(info.Size()), path),
+	}
+}
+
+func checkClientRunning() checkResult {
+	running, err := eve.ClientRunning()
+	if err != nil {
+		return checkResult{status: checkWarn, message: fmt.Sprintf("Eve client: could not check whether it is running: %v", err)}
+	}
+	if running {
+		return checkResult{
+			status:  checkWarn,
+			message: "Eve client: running",
+			fix:     "Close Eve before copying or restoring; the client overwrites settings files when it exits",
+		}
+	}
+	return checkResult{message: "Eve client: not running"}
+}
+
+// formatSize formats a byte count for humans.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
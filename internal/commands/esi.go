@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jpbriend/eve-settings-manager/internal/backup"
+	"github.com/jpbriend/eve-settings-manager/internal/config"
+	"github.com/jpbriend/eve-settings-manager/internal/esi"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+)
+
+var (
+	esiBaseURL        string
+	esiDatasourceFlag string
+	esiUserAgent      string
+
+	// esiConfig is the [esi] table of the configuration file.
+	esiConfig config.ESIConfig
+
+	// esiCache is the ESI cache shared by the clients of this run. It is
+	// loaded by the first newESIClient call and saved when the command
+	// completes.
+	esiCache *esi.Cache
+
+	// esiClients are the ESI clients created during this run.
+	esiClients []*esi.Client
+)
+
+// newESIClient creates an ESI client configured by the global flags and the
+// configuration file. It uses the cache of this run, the aliases of the
+// configuration file and the names recorded in backups, and does not contact
+// ESI with --offline.
+func newESIClient() *esi.Client {
+	opts := esiClientOptions()
+	if cache := loadESICache(); cache != nil {
+		opts = append(opts, esi.WithCache(cache))
+	}
+	opts = append(opts,
+		esi.WithOffline(offline),
+		esi.WithAliases(nameAliases),
+		esi.WithFallbackNames(backupNames),
+	)
+
+	client := esi.NewClient(opts...)
+	esiClients = append(esiClients, client)
+	return client
+}
+
+// esiClientOptions returns the options selecting the ESI to contact and how,
+// from the global flags and the configuration file.
+func esiClientOptions() []esi.Option {
+	var opts []esi.Option
+	if baseURL := firstNonEmpty(esiBaseURL, esiConfig.BaseURL); baseURL != "" {
+		opts = append(opts, esi.WithBaseURL(baseURL))
+	}
+	if datasource := esiDatasource(); datasource != "" {
+		opts = append(opts, esi.WithDatasource(datasource))
+	}
+	if userAgent := firstNonEmpty(esiUserAgent, esiConfig.UserAgent); userAgent != "" {
+		opts = append(opts, esi.WithUserAgent(userAgent))
+	}
+	return opts
+}
+
+// esiDatasource returns the ESI datasource given with --esi-datasource or the
+// configuration file. Without one, --server singularity selects Singularity.
+func esiDatasource() string {
+	if datasource := firstNonEmpty(esiDatasourceFlag, esiConfig.Datasource); datasource != "" {
+		return datasource
+	}
+	if serverFilter == eve.ServerSingularity {
+		return esi.DatasourceSingularity
+	}
+	return ""
+}
+
+// checkESIDatasource fails for a datasource ESI does not serve.
+func checkESIDatasource() error {
+	switch datasource := esiDatasource(); datasource {
+	case "", esi.DatasourceTranquility, esi.DatasourceSingularity:
+		return nil
+	default:
+		return fmt.Errorf("unknown ESI datasource '%s' (expected %s or %s)", datasource,
+			esi.DatasourceTranquility, esi.DatasourceSingularity)
+	}
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// backupNames returns the character names recorded in the backups next to
+// the settings, in the current folder and in the watch snapshots.
+func backupNames() map[int64]string {
+	dirs := []string{"."}
+	if dir, err := defaultSnapshotDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+
+	// Search problems were reported when the settings were searched
+	installs, _ := eve.DetectInstallations()
+	for _, install := range eve.FilterInstallations(installs, serverFilter) {
+		dirs = append(dirs, install.Path)
+		dirs = append(dirs, install.Profiles...)
+	}
+
+	return backup.CharacterNames(backup.FindBackups(dirs))
+}
+
+// loadESICache loads the ESI cache once per run. A cache that cannot be read
+// is replaced by an empty one; nil is returned if there is no cache location.
+func loadESICache() *esi.Cache {
+	if esiCache != nil {
+		return esiCache
+	}
+
+	path, err := esi.DefaultCachePath(esiDatasource())
+	if err != nil {
+		return nil
+	}
+
+	esiCache, err = esi.LoadCache(path)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: ignoring ESI cache: %v\n", err)
+		esiCache = esi.NewCache(path)
+	}
+	return esiCache
+}
+
+// finishESI saves the ESI cache if it was used during this run, and tells
+// when ESI could not be reached.
+func finishESI() {
+	for _, client := range esiClients {
+		if !offline && !client.Online() {
+			_, _ = fmt.Fprintln(os.Stderr, "Warning: ESI could not be reached; names came from the name cache, "+
+				"backups and aliases (use --offline to not try ESI)")
+			break
+		}
+	}
+
+	if esiCache == nil {
+		return
+	}
+	if err := esiCache.Save(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jpbriend/eve-settings-manager/internal/esi"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	inspectFormat string
+	inspectPath   string
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <character|path>",
+	Short: "Print the decoded contents of a settings file",
+	Long: `Decode a settings file and print its contents as JSON or YAML.
+
+The argument is either a path to a core_char_*.dat or core_user_*.dat file,
+or a character (ID or name) whose local settings file should be inspected.
+
+Use --path to print only part of the settings, e.g. --path overview.tabsettings.
+Path segments match dict keys or list indexes; (timestamp, value) entries are
+stepped through automatically.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().StringVar(&inspectFormat, "format", "json", "Output format (json or yaml)")
+	inspectCmd.Flags().StringVar(&inspectPath, "path", "", "Dot-separated path of the value to print")
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	if inspectFormat != "json" && inspectFormat != "yaml" {
+		return fmt.Errorf("unsupported format '%s' (use json or yaml)", inspectFormat)
+	}
+
+	path, err := locateSettingsFile(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	file, err := settings.Load(path)
+	if err != nil {
+		return err
+	}
+
+	value, err := settings.Select(file.Root, inspectPath)
+	if err != nil {
+		return err
+	}
+
+	return printPlain(settings.Plain(value), inspectFormat)
+}
+
+// printPlain writes v to stdout as pretty JSON or YAML.
+func printPlain(v any, format string) error {
+	if format == "yaml" {
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// locateSettingsFile returns arg if it names an existing file, otherwise the
+// local settings file of the character it identifies (ID or name).
+func locateSettingsFile(ctx context.Context, arg string) (string, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		return arg, nil
+	}
+
+	char, err := findLocalCharacter(ctx, newESIClient(), arg)
+	if err != nil {
+		return "", err
+	}
+	return char.FilePath, nil
+}
+
+// findLocalCharacter resolves a character (ID or name) and returns its local
+// settings file, asking which one if it has several.
+func findLocalCharacter(ctx context.Context, esiClient *esi.Client, identifier string) (*eve.CharacterSettings, error) {
+	charID, err := esiClient.ResolveCharacter(ctx, identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve character '%s': %w", identifier, err)
+	}
+
+	dirs, err := detectSettingsDirectories()
+	if err != nil {
+		return nil, err
+	}
+
+	characters, err := eve.FindCharacterSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return nil, fmt.Errorf("failed to find character settings: %w", err)
+	}
+
+	label := fmt.Sprintf("%s (%d)", esiClient.GetCharacterNameOrFallback(ctx, charID), charID)
+	char, err := chooseCharacterFile(label, eve.CharacterFiles(characters, charID), "--profile or --server")
+	if err != nil {
+		return nil, err
+	}
+	if char == nil {
+		return nil, fmt.Errorf("character '%s' (ID: %d) not found in local settings", identifier, charID)
+	}
+	return char, nil
+}
+
+// localCharacter is a character with local settings and its display name.
+type localCharacter struct {
+	char *eve.CharacterSettings
+	name string
+}
+
+// findLocalCharacters resolves several characters (IDs or names) with
+// findLocalCharacter, failing on the first one that cannot be found.
+func findLocalCharacters(ctx context.Context, esiClient *esi.Client, identifiers []string) ([]localCharacter, error) {
+	var found []localCharacter
+	for _, identifier := range identifiers {
+		char, err := findLocalCharacter(ctx, esiClient, identifier)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, localCharacter{
+			char: char,
+			name: esiClient.GetCharacterNameOrFallback(ctx, char.CharacterID),
+		})
+	}
+	return found, nil
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+var layoutForce bool
+
+var layoutCmd = &cobra.Command{
+	Use:   "layout",
+	Short: "Adjust window layouts",
+}
+
+var layoutRescaleCmd = &cobra.Command{
+	Use:   "rescale <character> <FROM:TO>",
+	Short: "Rescale a character's window layout to another resolution",
+	Long: `Rewrite the window positions and sizes of a character for a different
+screen resolution.
+
+Windows are scaled proportionally from the FROM resolution to the TO resolution
+and clamped so that they fit on the target screen. A backup of the settings is
+created before they are modified.`,
+	Example: `  esm layout rescale "Laptop Alt" 3840x2160:1920x1080`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    runLayoutRescale,
+}
+
+func init() {
+	layoutRescaleCmd.Flags().BoolVarP(&layoutForce, "force", "f", false, "Rescale without confirmation")
+
+	layoutCmd.AddCommand(layoutRescaleCmd)
+}
+
+func runLayoutRescale(cmd *cobra.Command, args []string) error {
+	from, to, err := settings.ParseRescale(args[1])
+	if err != nil {
+		return err
+	}
+
+	esiClient := newESIClient()
+	char, err := findLocalCharacter(cmd.Context(), esiClient, args[0])
+	if err != nil {
+		return err
+	}
+	name := esiClient.GetCharacterNameOrFallback(cmd.Context(), char.CharacterID)
+
+	file, err := settings.Load(char.FilePath)
+	if err != nil {
+		return err
+	}
+
+	n, err := settings.RescaleWindows(file, from, to)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		fmt.Printf("No window positions found for %s (%d).\n", name, char.CharacterID)
+		return nil
+	}
+
+	// Confirmation prompt
+	if !layoutForce {
+		fmt.Printf("\nAbout to rescale %d window(s) of %s (%d) from %s to %s\n", n, name, char.CharacterID, from, to)
+		ok, err := confirm("Proceed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	zipBackupPath, err := backupCharacterFile(char.CharacterID, name, char.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	fmt.Printf("Backup created: %s\n", zipBackupPath)
+
+	if err := file.Save(char.FilePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nRescaled %d window(s) of %s (%d) from %s to %s\n", n, name, char.CharacterID, from, to)
+	return nil
+}
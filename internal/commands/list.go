@@ -3,11 +3,12 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"text/tabwriter"
 	"time"
 
-	"github.com/jpbriend/eve-settings-manager/internal/esi"
 	"github.com/jpbriend/eve-settings-manager/internal/eve"
 	"github.com/spf13/cobra"
 )
@@ -15,10 +16,12 @@ import (
 // characterWithName combines character settings with resolved name for sorting.
 type characterWithName struct {
 	eve.CharacterSettings
-	Name string
+	Name    string
+	Account string
+	Copies  []eve.CharacterSettings // older settings files in other folders
 }
 
-var listVerbose bool
+var listUsers bool
 
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -26,23 +29,33 @@ var listCmd = &cobra.Command{
 	Long: `List all detected Eve Online character settings files.
 
 Scans known Eve settings locations and displays character IDs with their names
-(resolved via ESI API), modification times, and file paths.`,
+(resolved via ESI API), owning account user file, modification times, and file paths.
+
+The ACCOUNT column shows the account user ID owning each character. Pairings
+marked with ~ are inferred from file modification times; use 'esm account link'
+to record them explicitly.
+
+A SERVER column is shown when installations of several servers are found;
+use --server to list only one of them.
+
+Use --users to list account-wide settings files (core_user_*.dat) instead.`,
 	RunE: runList,
 }
 
 func init() {
-	listCmd.Flags().BoolVarP(&listVerbose, "verbose", "v", false, "Show additional details including full paths")
+	listCmd.Flags().BoolVar(&listUsers, "users", false, "List account user settings files instead of characters")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	// Detect settings directories
-	dirs, err := eve.DetectSettingsDirectories()
+	// Detect installations and their settings directories
+	installs, err := detectInstallations()
 	if err != nil {
-		return fmt.Errorf("failed to detect settings directories: %w", err)
+		return err
 	}
+	dirs := eve.SettingsDirectories(installs)
 
 	if len(dirs) == 0 {
-		fmt.Println("No Eve Online settings directories found.")
+		fmt.Printf("No Eve Online settings directories found%s.\n", filterDescription())
 		fmt.Println("\nSearched locations:")
 		for _, path := range eve.GetPossibleSettingsPaths() {
 			fmt.Printf("  - %s\n", path)
@@ -50,17 +63,24 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if listVerbose {
-		fmt.Println("Found settings directories:")
-		for _, dir := range dirs {
-			fmt.Printf("  - %s\n", dir)
+	if verbose {
+		fmt.Println("Found installations:")
+		for _, install := range installs {
+			fmt.Printf("  - %s (%s, %s)\n", install.Path, install.Server, install.Launcher)
+			for _, dir := range install.Profiles {
+				fmt.Printf("      %s\n", filepath.Base(dir))
+			}
 		}
 		fmt.Println()
 	}
 
+	if listUsers {
+		return listUserSettings(dirs)
+	}
+
 	// Find character settings files
 	characters, err := eve.FindCharacterSettings(dirs)
-	if err != nil {
+	if err = reportWarnings(err); err != nil {
 		return fmt.Errorf("failed to find character settings: %w", err)
 	}
 
@@ -69,20 +89,31 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Pair characters with their account user files
+	users, err := eve.FindUserSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return fmt.Errorf("failed to find user settings: %w", err)
+	}
+	accounts := observeAccounts(characters, users)
+
 	// Fetch character names from ESI
-	client := esi.NewClient()
-	charIDs := make([]int64, len(characters))
-	for i, c := range characters {
-		charIDs[i] = c.CharacterID
+	client := newESIClient()
+	groups := eve.GroupCharacters(characters)
+	charIDs := make([]int64, len(groups))
+	for i, files := range groups {
+		charIDs[i] = files[0].CharacterID
 	}
-	names := client.BatchGetCharacterNames(charIDs)
+	names := client.BatchGetCharacterNames(cmd.Context(), charIDs)
 
-	// Combine characters with names for sorting
-	charsWithNames := make([]characterWithName, len(characters))
-	for i, c := range characters {
+	// Combine characters with names for sorting, one row per character
+	charsWithNames := make([]characterWithName, len(groups))
+	for i, files := range groups {
+		c := files[0]
 		charsWithNames[i] = characterWithName{
 			CharacterSettings: c,
 			Name:              names[c.CharacterID],
+			Account:           accountLabel(accounts, c.CharacterID),
+			Copies:            files[1:],
 		}
 	}
 
@@ -95,24 +126,112 @@ func runList(cmd *cobra.Command, args []string) error {
 	})
 
 	// Display results
+	showServer := len(eve.Servers(installs)) > 1
+	showLocation := len(groups) < len(characters)
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	if listVerbose {
-		_, _ = fmt.Fprintln(w, "CHARACTER ID\tNAME\tMODIFIED\tPATH")
-	} else {
-		_, _ = fmt.Fprintln(w, "CHARACTER ID\tNAME\tMODIFIED")
+	header := "CHARACTER ID\tNAME\tACCOUNT"
+	if showServer {
+		header += "\tSERVER"
+	}
+	if showLocation {
+		header += "\tLOCATION"
+	}
+	header += "\tMODIFIED"
+	if verbose {
+		header += "\tPATH"
 	}
+	_, _ = fmt.Fprintln(w, header)
 
+	duplicates := 0
 	for _, c := range charsWithNames {
-		modTime := time.Unix(c.ModTime, 0).Format("2006-01-02 15:04:05")
+		_, _ = fmt.Fprintln(w, characterRow(fmt.Sprintf("%d\t%s\t%s", c.CharacterID, c.Name, c.Account),
+			c.CharacterSettings, installs, showServer, showLocation))
+
+		// Further copies of the character's settings in other folders
+		for _, dup := range c.Copies {
+			_, _ = fmt.Fprintln(w, characterRow("\t\t", dup, installs, showServer, showLocation))
+		}
+		if len(c.Copies) > 0 {
+			duplicates++
+		}
+	}
+	_ = w.Flush()
+
+	fmt.Printf("\nFound %d character(s)\n", len(groups))
+	if duplicates > 0 {
+		fmt.Printf("%d character(s) have settings in several folders; choose one with --profile or --server.\n",
+			duplicates)
+	}
+	return nil
+}
+
+// characterRow completes a list row starting with prefix (the ID, name and
+// account cells) with the server, location, modification time and path of c.
+func characterRow(prefix string, c eve.CharacterSettings, installs []eve.Installation, showServer, showLocation bool) string {
+	row := prefix
+	if showServer {
+		row += "\t" + eve.ServerOfPath(installs, c.FilePath)
+	}
+	if showLocation {
+		row += "\t" + settingsLocation(c.FilePath)
+	}
+	row += "\t" + time.Unix(c.ModTime, 0).Format("2006-01-02 15:04:05")
+	if verbose {
+		row += "\t" + c.FilePath
+	}
+	return row
+}
+
+// accountLabel formats the account pairing of a character for display.
+func accountLabel(accounts *eve.AccountStore, charID int64) string {
+	link, ok := accounts.Resolve(charID)
+	if !ok {
+		return "-"
+	}
+	if link.Source == eve.AccountInferred {
+		return fmt.Sprintf("~%d", link.UserID)
+	}
+	return strconv.FormatInt(link.UserID, 10)
+}
+
+// listUserSettings displays the account user settings files found in dirs.
+func listUserSettings(dirs []string) error {
+	users, err := eve.FindUserSettings(dirs)
+	if err = reportWarnings(err); err != nil {
+		return fmt.Errorf("failed to find user settings: %w", err)
+	}
+
+	if len(users) == 0 {
+		fmt.Println("No user settings files found.")
+		return nil
+	}
+
+	// Sort by Modified desc, then ID asc
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].ModTime != users[j].ModTime {
+			return users[i].ModTime > users[j].ModTime // desc
+		}
+		return users[i].UserID < users[j].UserID // asc
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if verbose {
+		_, _ = fmt.Fprintln(w, "USER ID\tMODIFIED\tPATH")
+	} else {
+		_, _ = fmt.Fprintln(w, "USER ID\tMODIFIED")
+	}
+
+	for _, u := range users {
+		modTime := time.Unix(u.ModTime, 0).Format("2006-01-02 15:04:05")
 
-		if listVerbose {
-			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", c.CharacterID, c.Name, modTime, c.FilePath)
+		if verbose {
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", u.UserID, modTime, u.FilePath)
 		} else {
-			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", c.CharacterID, c.Name, modTime)
+			_, _ = fmt.Fprintf(w, "%d\t%s\n", u.UserID, modTime)
 		}
 	}
 	_ = w.Flush()
 
-	fmt.Printf("\nFound %d character(s)\n", len(characters))
+	fmt.Printf("\nFound %d user file(s)\n", len(users))
 	return nil
 }
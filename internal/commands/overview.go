@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	overviewOutput string
+	overviewTo     []string
+	overviewForce  bool
+)
+
+var overviewCmd = &cobra.Command{
+	Use:   "overview",
+	Short: "Export and import overview packs",
+	Long: `Export and import overview settings as YAML overview packs.
+
+Packs use the same layout as the overview files shared from the game client:
+tabs, presets, columns and label settings. They are read from and written to
+the characters' settings files directly, without logging in.`,
+}
+
+var overviewExportCmd = &cobra.Command{
+	Use:   "export <character|path>",
+	Short: "Export a character's overview to a YAML pack",
+	Long: `Export the overview tabs, presets and column configuration of a character
+(ID, name or settings file path) as a YAML overview pack.
+
+The pack is written to stdout unless -o is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOverviewExport,
+}
+
+var overviewImportCmd = &cobra.Command{
+	Use:   "import <pack.yaml>",
+	Short: "Import a YAML overview pack into characters",
+	Long: `Import a YAML overview pack into one or more characters.
+
+Only the overview entries contained in the pack are replaced; all other
+settings are kept. A backup of every target is created before it is modified.`,
+	Example: `  esm overview import fleet.yaml --to "Main Character"
+  esm overview import fleet.yaml --to 12345678,87654321`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOverviewImport,
+}
+
+func init() {
+	overviewExportCmd.Flags().StringVarP(&overviewOutput, "output", "o", "", "Output file (default stdout)")
+
+	overviewImportCmd.Flags().StringSliceVar(&overviewTo, "to", nil, "Target characters (IDs or names, comma-separated)")
+	overviewImportCmd.Flags().BoolVarP(&overviewForce, "force", "f", false, "Import without confirmation")
+	_ = overviewImportCmd.MarkFlagRequired("to")
+
+	overviewCmd.AddCommand(overviewExportCmd)
+	overviewCmd.AddCommand(overviewImportCmd)
+}
+
+func runOverviewExport(cmd *cobra.Command, args []string) error {
+	file, label, err := loadCharacterSettings(cmd.Context(), newESIClient(), args[0])
+	if err != nil {
+		return err
+	}
+
+	pack, err := settings.ExportOverview(file)
+	if err != nil {
+		return err
+	}
+
+	if overviewOutput == "" {
+		return pack.Encode(os.Stdout)
+	}
+
+	if err := pack.Save(overviewOutput); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported overview of %s to %s\n", label, overviewOutput)
+	return nil
+}
+
+func runOverviewImport(cmd *cobra.Command, args []string) error {
+	pack, err := settings.LoadOverviewPack(args[0])
+	if err != nil {
+		return err
+	}
+
+	esiClient := newESIClient()
+
+	// Resolve every target before touching any file
+	targets, err := findLocalCharacters(cmd.Context(), esiClient, overviewTo)
+	if err != nil {
+		return err
+	}
+
+	// Confirmation prompt
+	if !overviewForce {
+		fmt.Printf("\nAbout to import overview pack %s into:\n", args[0])
+		for _, t := range targets {
+			fmt.Printf("  %s (%d)\n", t.name, t.char.CharacterID)
+		}
+		ok, err := confirm("Proceed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	for _, t := range targets {
+		file, err := settings.Load(t.char.FilePath)
+		if err != nil {
+			return err
+		}
+
+		imported, skipped, err := settings.ImportOverview(file, pack)
+		if err != nil {
+			return err
+		}
+		if len(imported) == 0 {
+			return fmt.Errorf("overview pack %s contains no known overview settings", args[0])
+		}
+
+		zipBackupPath, err := backupCharacterFile(t.char.CharacterID, t.name, t.char.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to create backup of %s: %w", t.name, err)
+		}
+
+		if err := file.Save(t.char.FilePath); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nImported overview into %s (%d)\n", t.name, t.char.CharacterID)
+		fmt.Printf("  Backup:   %s\n", zipBackupPath)
+		fmt.Printf("  Imported: %s\n", strings.Join(imported, ", "))
+		if len(skipped) > 0 {
+			fmt.Printf("  Skipped unknown keys: %s\n", strings.Join(skipped, ", "))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"golang.org/x/term"
+)
+
+// chooseCharacterFile returns the settings file to use for a character among
+// files, which are sorted like eve.CharacterFiles. When there are several, the
+// user picks one on a terminal; otherwise an error lists them together with
+// hint, the flags that select one. It returns nil if files is empty.
+func chooseCharacterFile(label string, files []eve.CharacterSettings, hint string) (*eve.CharacterSettings, error) {
+	paths := make([]string, len(files))
+	modTimes := make([]int64, len(files))
+	for i, f := range files {
+		paths[i], modTimes[i] = f.FilePath, f.ModTime
+	}
+
+	i, err := chooseFile(label, paths, modTimes, hint)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+	return &files[i], nil
+}
+
+// chooseUserFile is like chooseCharacterFile for account user files, sorted
+// like eve.UserFiles.
+func chooseUserFile(label string, files []eve.UserSettings, hint string) (*eve.UserSettings, error) {
+	paths := make([]string, len(files))
+	modTimes := make([]int64, len(files))
+	for i, f := range files {
+		paths[i], modTimes[i] = f.FilePath, f.ModTime
+	}
+
+	i, err := chooseFile(label, paths, modTimes, hint)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+	return &files[i], nil
+}
+
+// chooseFile returns the index of the settings file to use among paths,
+// modified at modTimes, as described in chooseCharacterFile. It returns -1 if
+// paths is empty.
+func chooseFile(label string, paths []string, modTimes []int64, hint string) (int, error) {
+	switch len(paths) {
+	case 0:
+		return -1, nil
+	case 1:
+		return 0, nil
+	}
+
+	var lines []string
+	for i, path := range paths {
+		lines = append(lines, fmt.Sprintf("  %d) %s (modified %s)", i+1, path,
+			time.Unix(modTimes[i], 0).Format("2006-01-02 15:04:05")))
+	}
+
+	if !isTerminal(os.Stdin) {
+		return -1, fmt.Errorf("%s has settings in %d folders; choose one with %s:\n%s",
+			label, len(paths), hint, strings.Join(lines, "\n"))
+	}
+
+	fmt.Printf("%s has settings in %d folders:\n%s\n", label, len(paths), strings.Join(lines, "\n"))
+	fmt.Printf("Which one? [1-%d]: ", len(paths))
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(response))
+	if err != nil || choice < 1 || choice > len(paths) {
+		return -1, fmt.Errorf("no settings file chosen for %s", label)
+	}
+	return choice - 1, nil
+}
+
+// confirm asks prompt as a yes/no question on stdin and reports whether the
+// user answered yes. No answer, as at the end of the input, means no.
+func confirm(prompt string) (bool, error) {
+	fmt.Printf("\n%s [y/N]: ", prompt)
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// settingsLocation names the installation and profile folder of a settings
+// file, e.g. c_eve_sharedcache_tq_tranquility/settings_Default.
+func settingsLocation(path string) string {
+	dir := filepath.Dir(path)
+	return filepath.Join(filepath.Base(filepath.Dir(dir)), filepath.Base(dir))
+}
@@ -0,0 +1,299 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jpbriend/eve-settings-manager/internal/backup"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileFrom  string
+	profileForce bool
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage settings profiles (settings_* folders)",
+	Long: `Manage the settings profiles of your Eve installations.
+
+Each installation keeps its settings in profile folders named settings_<name>,
+e.g. settings_Default. Profiles are given by name (with or without the
+settings_ prefix) or by folder path. If several installations have a profile
+of the same name, use --server or pass the folder path.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings profiles",
+	RunE:  runProfileList,
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile by cloning an existing one",
+	Example: `  esm profile create PvP
+  esm profile create Mining --from PvP`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileCreate,
+}
+
+var profileRenameCmd = &cobra.Command{
+	Use:   "rename <profile> <new-name>",
+	Short: "Rename a profile",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runProfileRename,
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <profile>",
+	Short: "Delete a profile after backing it up",
+	Long: `Delete a settings profile folder.
+
+A ZIP backup of the whole folder is created next to it first. Characters and
+account files in it can be restored with 'esm restore'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileDelete,
+}
+
+var profileDiffCmd = &cobra.Command{
+	Use:   "diff <profile> <profile>",
+	Short: "Show which files differ between two profiles",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runProfileDiff,
+}
+
+func init() {
+	profileCreateCmd.Flags().StringVar(&profileFrom, "from", "Default", "Profile to clone")
+	profileDeleteCmd.Flags().BoolVarP(&profileForce, "force", "f", false, "Delete without confirmation")
+
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileRenameCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileDiffCmd)
+}
+
+// findProfile returns the profile named by identifier (a name or a folder
+// path) among the installations selected with --server.
+func findProfile(identifier string) (*eve.Profile, error) {
+	installs, err := detectInstallations()
+	if err != nil {
+		return nil, err
+	}
+	return eve.FindProfile(eve.Profiles(installs), identifier)
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	installs, err := detectInstallations()
+	if err != nil {
+		return err
+	}
+
+	profiles := eve.Profiles(installs)
+	if len(profiles) == 0 {
+		fmt.Println("No settings profiles found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if verbose {
+		_, _ = fmt.Fprintln(w, "PROFILE\tSERVER\tINSTALLATION\tCHARACTERS\tUSERS\tPATH")
+	} else {
+		_, _ = fmt.Fprintln(w, "PROFILE\tSERVER\tINSTALLATION\tCHARACTERS\tUSERS")
+	}
+
+	for _, p := range profiles {
+		chars, err := eve.FindCharacterSettings([]string{p.Path})
+		if err = reportWarnings(err); err != nil {
+			return fmt.Errorf("failed to find character settings: %w", err)
+		}
+		users, err := eve.FindUserSettings([]string{p.Path})
+		if err = reportWarnings(err); err != nil {
+			return fmt.Errorf("failed to find user settings: %w", err)
+		}
+
+		if verbose {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", p.Name, p.Installation.Server, p.Installation.Name,
+				len(chars), len(users), p.Path)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", p.Name, p.Installation.Server, p.Installation.Name,
+				len(chars), len(users))
+		}
+	}
+	_ = w.Flush()
+
+	fmt.Printf("\nFound %d profile(s)\n", len(profiles))
+	return nil
+}
+
+func runProfileCreate(cmd *cobra.Command, args []string) error {
+	source, err := findProfile(profileFrom)
+	if err != nil {
+		return err
+	}
+
+	path, err := eve.CloneProfile(source.Path, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created profile %s from %s\n", eve.ProfileName(path), source.Name)
+	fmt.Printf("  Path: %s\n", path)
+	return nil
+}
+
+func runProfileRename(cmd *cobra.Command, args []string) error {
+	profile, err := findProfile(args[0])
+	if err != nil {
+		return err
+	}
+
+	path, err := eve.RenameProfile(profile.Path, args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed profile %s to %s\n", profile.Name, eve.ProfileName(path))
+	fmt.Printf("  Path: %s\n", path)
+	return nil
+}
+
+func runProfileDelete(cmd *cobra.Command, args []string) error {
+	profile, err := findProfile(args[0])
+	if err != nil {
+		return err
+	}
+
+	chars, err := eve.FindCharacterSettings([]string{profile.Path})
+	if err = reportWarnings(err); err != nil {
+		return fmt.Errorf("failed to find character settings: %w", err)
+	}
+	users, err := eve.FindUserSettings([]string{profile.Path})
+	if err = reportWarnings(err); err != nil {
+		return fmt.Errorf("failed to find user settings: %w", err)
+	}
+
+	// Confirmation prompt
+	if !profileForce {
+		fmt.Printf("\nAbout to delete profile %s:\n", profile.Name)
+		fmt.Printf("  Path: %s\n", profile.Path)
+		fmt.Printf("  Contains %d character(s) and %d account user file(s)\n", len(chars), len(users))
+		ok, err := confirm("Proceed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	// Back up the whole folder next to it
+	charIDs := make([]int64, len(chars))
+	for i, c := range chars {
+		charIDs[i] = c.CharacterID
+	}
+	names := newESIClient().BatchGetCharacterNames(cmd.Context(), charIDs)
+
+	charBackups := make([]backup.CharacterBackup, len(chars))
+	for i, c := range chars {
+		charBackups[i] = backup.CharacterBackup{
+			CharacterID:   c.CharacterID,
+			CharacterName: names[c.CharacterID],
+			OriginalPath:  c.FilePath,
+			FileName:      filepath.Base(c.FilePath),
+		}
+	}
+	userBackups := make([]backup.UserBackup, len(users))
+	for i, u := range users {
+		userBackups[i] = backup.UserBackup{
+			UserID:       u.UserID,
+			OriginalPath: u.FilePath,
+			FileName:     filepath.Base(u.FilePath),
+		}
+	}
+
+	zipBackupPath := filepath.Join(filepath.Dir(profile.Path), fmt.Sprintf("backup_profile_%s_%s.zip",
+		profile.Name, time.Now().Format("20060102_150405")))
+	if err := backup.CreateProfileBackup(zipBackupPath, profile.Path, charBackups, userBackups); err != nil {
+		return fmt.Errorf("failed to back up profile: %w", err)
+	}
+	fmt.Printf("Backup created: %s\n", zipBackupPath)
+
+	if err := os.RemoveAll(profile.Path); err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+
+	fmt.Printf("Deleted profile %s\n", profile.Name)
+	return nil
+}
+
+func runProfileDiff(cmd *cobra.Command, args []string) error {
+	first, err := findProfile(args[0])
+	if err != nil {
+		return err
+	}
+	second, err := findProfile(args[1])
+	if err != nil {
+		return err
+	}
+
+	diffs, err := eve.CompareProfiles(first.Path, second.Path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Comparing profiles:\n")
+	fmt.Printf("  First:  %s (%s)\n", first.Name, first.Path)
+	fmt.Printf("  Second: %s (%s)\n", second.Name, second.Path)
+
+	if len(diffs) == 0 {
+		fmt.Println("\nNo differences found.")
+		return nil
+	}
+
+	fmt.Println()
+	for _, d := range diffs {
+		switch d.Status {
+		case eve.FileOnlyInFirst:
+			fmt.Printf("  - %s (only in %s)\n", d.Name, first.Name)
+		case eve.FileOnlyInSecond:
+			fmt.Printf("  + %s (only in %s)\n", d.Name, second.Name)
+		case eve.FileDiffers:
+			fmt.Printf("  ~ %s%s\n", d.Name, settingChanges(filepath.Join(first.Path, d.Name), filepath.Join(second.Path, d.Name)))
+		}
+	}
+
+	fmt.Printf("\n%d file(s) differ\n", len(diffs))
+	return nil
+}
+
+// settingChanges describes how many settings differ between two settings
+// files, or returns "" if they cannot be decoded.
+func settingChanges(firstPath, secondPath string) string {
+	if filepath.Ext(firstPath) != ".dat" {
+		return ""
+	}
+
+	first, err := settings.Load(firstPath)
+	if err != nil {
+		return ""
+	}
+	second, err := settings.Load(secondPath)
+	if err != nil {
+		return ""
+	}
+
+	changes, err := settings.Diff(first, second)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%d setting change(s))", len(changes))
+}
@@ -1,19 +1,17 @@
 package commands
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 
 	"github.com/jpbriend/eve-settings-manager/internal/backup"
-	"github.com/jpbriend/eve-settings-manager/internal/eve"
 	"github.com/spf13/cobra"
 )
 
 var (
 	restoreCharacter string
+	restoreUser      int64
 	restoreForce     bool
 )
 
@@ -22,14 +20,16 @@ var restoreCmd = &cobra.Command{
 	Short: "Restore character settings from a backup",
 	Long: `Restore character settings from a ZIP backup file.
 
-By default, restores all characters in the backup to their original locations.
-Use --character to restore a specific character only.`,
+By default, restores all characters and account user files in the backup to
+their original locations. Use --character to restore a specific character only,
+or --user to restore a specific account user file only.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runRestore,
 }
 
 func init() {
 	restoreCmd.Flags().StringVarP(&restoreCharacter, "character", "c", "", "Restore specific character (ID or name)")
+	restoreCmd.Flags().Int64Var(&restoreUser, "user", 0, "Restore specific account user file (user ID)")
 	restoreCmd.Flags().BoolVarP(&restoreForce, "force", "f", false, "Restore without confirmation")
 }
 
@@ -49,9 +49,29 @@ func runRestore(cmd *cobra.Command, args []string) error {
 	for _, c := range metadata.Characters {
 		fmt.Printf("  - %s (%d)\n", c.CharacterName, c.CharacterID)
 	}
+	if len(metadata.Users) > 0 {
+		fmt.Printf("User files in backup:\n")
+		for _, u := range metadata.Users {
+			fmt.Printf("  - core_user_%d.dat\n", u.UserID)
+		}
+	}
 
-	// Determine which characters to restore
+	// Determine which characters and users to restore
 	var charactersToRestore []backup.CharacterBackup
+	var usersToRestore []backup.UserBackup
+
+	if restoreUser != 0 {
+		for _, u := range metadata.Users {
+			if u.UserID == restoreUser {
+				usersToRestore = append(usersToRestore, u)
+				break
+			}
+		}
+
+		if len(usersToRestore) == 0 {
+			return fmt.Errorf("user %d not found in backup", restoreUser)
+		}
+	}
 
 	if restoreCharacter != "" {
 		// Find specific character
@@ -76,18 +96,15 @@ func runRestore(cmd *cobra.Command, args []string) error {
 		if len(charactersToRestore) == 0 {
 			return fmt.Errorf("character '%s' not found in backup", restoreCharacter)
 		}
-	} else {
+	} else if restoreUser == 0 {
 		charactersToRestore = metadata.Characters
+		usersToRestore = metadata.Users
 	}
 
 	// Check if we have Eve settings directories to restore to
-	dirs, err := eve.DetectSettingsDirectories()
+	dirs, err := detectSettingsDirectories()
 	if err != nil {
-		return fmt.Errorf("failed to detect settings directories: %w", err)
-	}
-
-	if len(dirs) == 0 {
-		return fmt.Errorf("no Eve Online settings directories found - cannot restore")
+		return err
 	}
 
 	// Determine restore paths
@@ -95,33 +112,27 @@ func runRestore(cmd *cobra.Command, args []string) error {
 	restorePaths := make(map[int64]string)
 	for _, c := range charactersToRestore {
 		// Try to use original path if it exists and is in a valid settings dir
-		restorePath := c.OriginalPath
-		pathValid := false
-
-		for _, dir := range dirs {
-			if strings.HasPrefix(c.OriginalPath, dir) {
-				pathValid = true
-				break
-			}
-		}
-
-		if !pathValid {
-			// Use first available settings directory
-			restorePath = fmt.Sprintf("%s/core_char_%d.dat", dirs[0], c.CharacterID)
-		}
-
+		restorePath := resolveRestorePath(c.OriginalPath, dirs,
+			fmt.Sprintf("core_char_%d.dat", c.CharacterID))
 		restorePaths[c.CharacterID] = restorePath
 		fmt.Printf("  %s (%d) -> %s\n", c.CharacterName, c.CharacterID, restorePath)
 	}
 
+	userRestorePaths := make(map[int64]string)
+	for _, u := range usersToRestore {
+		restorePath := resolveRestorePath(u.OriginalPath, dirs,
+			fmt.Sprintf("core_user_%d.dat", u.UserID))
+		userRestorePaths[u.UserID] = restorePath
+		fmt.Printf("  core_user_%d.dat -> %s\n", u.UserID, restorePath)
+	}
+
 	// Confirmation prompt
 	if !restoreForce {
-		fmt.Print("\nProceed with restore? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		ok, err := confirm("Proceed with restore?")
+		if err != nil {
+			return err
+		}
+		if !ok {
 			fmt.Println("Operation cancelled.")
 			return nil
 		}
@@ -136,6 +147,28 @@ func runRestore(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Restored: %s (%d)\n", c.CharacterName, c.CharacterID)
 	}
 
-	fmt.Printf("\nRestore completed successfully! %d character(s) restored.\n", len(charactersToRestore))
+	for _, u := range usersToRestore {
+		destPath := userRestorePaths[u.UserID]
+		if err := backup.ExtractUser(backupFile, u.UserID, destPath); err != nil {
+			return fmt.Errorf("failed to restore user %d: %w", u.UserID, err)
+		}
+		fmt.Printf("Restored: core_user_%d.dat\n", u.UserID)
+	}
+
+	fmt.Printf("\nRestore completed successfully! %d character(s) and %d user file(s) restored.\n",
+		len(charactersToRestore), len(usersToRestore))
 	return nil
 }
+
+// resolveRestorePath returns originalPath if it lies in one of the detected
+// settings directories, otherwise fileName in the first available directory.
+func resolveRestorePath(originalPath string, dirs []string, fileName string) string {
+	for _, dir := range dirs {
+		if strings.HasPrefix(originalPath, dir) {
+			return originalPath
+		}
+	}
+
+	// Use first available settings directory
+	return fmt.Sprintf("%s/%s", dirs[0], fileName)
+}
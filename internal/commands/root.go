@@ -1,27 +1,217 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jpbriend/eve-settings-manager/internal/config"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
 	"github.com/spf13/cobra"
 )
 
+var (
+	serverFilter  string
+	profileFilter string
+	settingsRoots []string
+	verbose       bool
+	strict        bool
+	offline       bool
+
+	// nameAliases are the character aliases of the configuration file.
+	nameAliases map[string]int64
+
+	// reportedWarnings holds the search problems already printed, so that
+	// several searches of the same folders print them once.
+	reportedWarnings = make(map[string]bool)
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "esm",
 	Short: "Eve Settings Manager - Manage Eve Online character settings",
 	Long: `Eve Settings Manager (esm) is a CLI tool to manage Eve Online character settings.
 
 It supports listing, copying, backing up, and restoring character-specific settings
-(core_char_*.dat files) across different accounts and installations.
+(core_char_*.dat files) and account-wide settings (core_user_*.dat files) across
+different accounts and installations.
+
+Works with both Steam and non-Steam versions on Windows, Linux and macOS.
+
+Use --server to act only on the installations of one server (tranquility,
+singularity, serenity or thunderdome) when several are installed.
 
-Works with both Steam and non-Steam versions on Windows and Linux.`,
+Settings folders in other places can be added with --settings-root, the
+ESM_SETTINGS_ROOT environment variable or the configuration file (see
+'esm config').
+
+Character names are looked up with ESI. When it cannot be reached, or with
+--offline, names come from the name cache (see 'esm cache'), the backups
+found next to the settings, in the current folder and in the watch
+snapshots, and the aliases of the configuration file.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// A broken configuration file must not prevent fixing it
+		fixingConfig := cmd.Parent() == configCmd
+		if err := applyConfig(); err != nil && !fixingConfig {
+			return err
+		}
+
+		if serverFilter != "" {
+			server, err := eve.ParseServer(serverFilter)
+			if err != nil {
+				return err
+			}
+			serverFilter = server
+		}
+		if fixingConfig {
+			return nil
+		}
+		return checkESIDatasource()
+	},
 }
 
 func Execute() error {
+	defer finishESI()
 	return rootCmd.Execute()
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&serverFilter, "server", "",
+		"Only use installations of this server (tranquility, singularity, serenity, thunderdome)")
+	rootCmd.PersistentFlags().StringVar(&profileFilter, "profile", "",
+		"Only use settings profiles with this name (e.g. Default or settings_PvP)")
+	rootCmd.PersistentFlags().StringSliceVar(&settingsRoots, "settings-root", nil,
+		"Extra folder to search for Eve settings (can be repeated)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false,
+		"Show additional details, including folders and files that could not be searched")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false,
+		"Fail if any folder or file could not be searched")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false,
+		"Never contact ESI; resolve names from the name cache, backups and aliases")
+	rootCmd.PersistentFlags().StringVar(&esiBaseURL, "esi-url", "",
+		"Address of ESI or of a mirror (default https://esi.evetech.net/latest)")
+	rootCmd.PersistentFlags().StringVar(&esiDatasourceFlag, "esi-datasource", "",
+		"Server ESI answers for: tranquility or singularity (default follows --server)")
+	rootCmd.PersistentFlags().StringVar(&esiUserAgent, "esi-user-agent", "",
+		"User-Agent sent to ESI; CCP asks for contact details")
+
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(backupCmd)
 	rootCmd.AddCommand(copyCmd)
 	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(accountCmd)
+	rootCmd.AddCommand(inspectCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(overviewCmd)
+	rootCmd.AddCommand(shortcutsCmd)
+	rootCmd.AddCommand(layoutCmd)
+	rootCmd.AddCommand(profileCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// applyConfig loads the configuration file and sets the settings search
+// options from it, ESM_SETTINGS_ROOT and --settings-root, and the ESI
+// options from it and --offline.
+func applyConfig() error {
+	cfg := &config.Config{}
+	if path, err := config.DefaultPath(); err == nil {
+		if cfg, err = config.Load(path); err != nil {
+			return err
+		}
+	}
+
+	var roots []string
+	roots = append(roots, cfg.SettingsRoots...)
+	roots = append(roots, config.EnvRoots()...)
+	roots = append(roots, settingsRoots...)
+
+	eve.SetSearchOptions(eve.SearchOptions{
+		ExtraRoots:   roots,
+		WinePrefixes: cfg.WinePrefixes,
+		Exclude:      cfg.Exclude,
+	})
+
+	offline = offline || cfg.Offline
+	nameAliases = cfg.Aliases
+	esiConfig = cfg.ESI
+	return nil
+}
+
+// reportWarnings handles the error of a settings search. Warnings about
+// folders or files that could not be searched are returned with --strict;
+// otherwise they are printed (in full with --verbose) and nil is returned.
+// Other errors are returned as they are.
+func reportWarnings(err error) error {
+	var warnings eve.Warnings
+	if !errors.As(err, &warnings) || strict {
+		return err
+	}
+
+	var fresh eve.Warnings
+	for _, w := range warnings {
+		if !reportedWarnings[w.Error()] {
+			reportedWarnings[w.Error()] = true
+			fresh = append(fresh, w)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	if verbose {
+		for _, w := range fresh {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: could not search %s: %v\n", w.Path, w.Err)
+		}
+	} else {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %d folder(s) or file(s) could not be searched "+
+			"(use --verbose to see them, or 'esm doctor')\n", len(fresh))
+	}
+	return nil
+}
+
+// detectInstallations finds the Eve installations, restricted to the server
+// selected with --server and the profiles selected with --profile. Search
+// problems are handled by reportWarnings.
+func detectInstallations() ([]eve.Installation, error) {
+	installs, err := eve.DetectInstallations()
+	if err = reportWarnings(err); err != nil {
+		return nil, fmt.Errorf("failed to detect settings directories: %w", err)
+	}
+	installs = eve.FilterInstallations(installs, serverFilter)
+	return eve.FilterProfiles(installs, profileFilter), nil
+}
+
+// detectSettingsDirectories returns the settings directories of the
+// installations and profiles selected with --server and --profile. It fails
+// if there are none.
+func detectSettingsDirectories() ([]string, error) {
+	installs, err := detectInstallations()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := eve.SettingsDirectories(installs)
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no Eve Online settings directories found%s", filterDescription())
+	}
+	return dirs, nil
+}
+
+// filterDescription describes the --server and --profile selection for
+// messages, e.g. " for server singularity".
+func filterDescription() string {
+	var parts []string
+	if serverFilter != "" {
+		parts = append(parts, "server "+serverFilter)
+	}
+	if profileFilter != "" {
+		parts = append(parts, "profile "+strings.TrimPrefix(profileFilter, eve.ProfilePrefix))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " for " + strings.Join(parts, " and ")
 }
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jpbriend/eve-settings-manager/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shortcutsOutput string
+	shortcutsFormat string
+	shortcutsTo     []string
+	shortcutsForce  bool
+)
+
+var shortcutsCmd = &cobra.Command{
+	Use:   "shortcuts",
+	Short: "List, export and import keyboard shortcuts",
+	Long: `Work with the keyboard shortcuts stored in character settings.
+
+Chords are written as key names joined by '+', e.g. "Ctrl+Shift+F1" or "Alt+M".
+Keys without a name are written as hex virtual-key codes such as "0xE2".`,
+}
+
+var shortcutsListCmd = &cobra.Command{
+	Use:   "list <character|path>",
+	Short: "List a character's keyboard shortcuts",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShortcutsList,
+}
+
+var shortcutsExportCmd = &cobra.Command{
+	Use:   "export <character|path>",
+	Short: "Export a character's keyboard shortcuts to TOML or JSON",
+	Long: `Export the keyboard shortcuts of a character (ID, name or settings file path)
+to a human-editable TOML or JSON file.
+
+The format follows the extension of the -o file and defaults to TOML. The file
+is written to stdout unless -o is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShortcutsExport,
+}
+
+var shortcutsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import keyboard shortcuts into characters",
+	Long: `Import keyboard shortcuts from a TOML or JSON file into one or more characters.
+
+Only the actions listed in the file are changed; an empty chord unbinds the
+action. A backup of every target is created before it is modified.`,
+	Example: `  esm shortcuts import keys.toml --to "Main Character"
+  esm shortcuts import keys.json --to 12345678,87654321`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShortcutsImport,
+}
+
+var shortcutsConflictsCmd = &cobra.Command{
+	Use:   "conflicts <character|path>",
+	Short: "Report chords bound to more than one action",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShortcutsConflicts,
+}
+
+func init() {
+	shortcutsExportCmd.Flags().StringVarP(&shortcutsOutput, "output", "o", "", "Output file (default stdout)")
+	shortcutsExportCmd.Flags().StringVar(&shortcutsFormat, "format", "", "Output format (toml or json, default from file extension)")
+
+	shortcutsImportCmd.Flags().StringSliceVar(&shortcutsTo, "to", nil, "Target characters (IDs or names, comma-separated)")
+	shortcutsImportCmd.Flags().BoolVarP(&shortcutsForce, "force", "f", false, "Import without confirmation")
+	_ = shortcutsImportCmd.MarkFlagRequired("to")
+
+	shortcutsCmd.AddCommand(shortcutsListCmd)
+	shortcutsCmd.AddCommand(shortcutsExportCmd)
+	shortcutsCmd.AddCommand(shortcutsImportCmd)
+	shortcutsCmd.AddCommand(shortcutsConflictsCmd)
+}
+
+// loadShortcuts decodes the shortcuts of a character (ID, name or path).
+func loadShortcuts(ctx context.Context, identifier string) ([]settings.Shortcut, string, error) {
+	file, label, err := loadCharacterSettings(ctx, newESIClient(), identifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	shortcuts, err := settings.Shortcuts(file)
+	if err != nil {
+		return nil, "", err
+	}
+	return shortcuts, label, nil
+}
+
+func runShortcutsList(cmd *cobra.Command, args []string) error {
+	shortcuts, label, err := loadShortcuts(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(shortcuts) == 0 {
+		fmt.Printf("No keyboard shortcuts found for %s.\n", label)
+		return nil
+	}
+
+	conflicting := make(map[string]bool)
+	for _, c := range settings.ShortcutConflicts(shortcuts) {
+		for _, action := range c.Actions {
+			conflicting[action] = true
+		}
+	}
+
+	fmt.Printf("Keyboard shortcuts of %s:\n\n", label)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ACTION\tKEYS\t")
+	_, _ = fmt.Fprintln(w, "------\t----\t")
+	for _, s := range shortcuts {
+		chord := s.Chord()
+		if chord == "" {
+			chord = "-"
+		}
+		if conflicting[s.Action] {
+			chord += " (!)"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t\n", s.Action, chord)
+	}
+	_ = w.Flush()
+
+	fmt.Printf("\nTotal: %d shortcut(s)\n", len(shortcuts))
+	if len(conflicting) > 0 {
+		fmt.Println("(!) conflicting binding, see 'esm shortcuts conflicts'")
+	}
+	return nil
+}
+
+func runShortcutsExport(cmd *cobra.Command, args []string) error {
+	format := shortcutsFormat
+	if format == "" {
+		format = settings.ShortcutFormat(shortcutsOutput)
+	}
+
+	shortcuts, label, err := loadShortcuts(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := settings.NewShortcutFile(shortcuts).Encode(format)
+	if err != nil {
+		return err
+	}
+
+	if shortcutsOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(shortcutsOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write shortcut file: %w", err)
+	}
+
+	fmt.Printf("Exported %d shortcut(s) of %s to %s\n", len(shortcuts), label, shortcutsOutput)
+	return nil
+}
+
+func runShortcutsImport(cmd *cobra.Command, args []string) error {
+	sf, err := settings.LoadShortcutFile(args[0])
+	if err != nil {
+		return err
+	}
+	bindings, err := sf.Bindings()
+	if err != nil {
+		return err
+	}
+
+	esiClient := newESIClient()
+
+	// Resolve every target before touching any file
+	targets, err := findLocalCharacters(cmd.Context(), esiClient, shortcutsTo)
+	if err != nil {
+		return err
+	}
+
+	// Confirmation prompt
+	if !shortcutsForce {
+		fmt.Printf("\nAbout to import %d shortcut(s) from %s into:\n", len(bindings), args[0])
+		for _, t := range targets {
+			fmt.Printf("  %s (%d)\n", t.name, t.char.CharacterID)
+		}
+		ok, err := confirm("Proceed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+
+	for _, t := range targets {
+		file, err := settings.Load(t.char.FilePath)
+		if err != nil {
+			return err
+		}
+
+		if err := settings.SetShortcuts(file, bindings); err != nil {
+			return err
+		}
+		merged, err := settings.Shortcuts(file)
+		if err != nil {
+			return err
+		}
+
+		zipBackupPath, err := backupCharacterFile(t.char.CharacterID, t.name, t.char.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to create backup of %s: %w", t.name, err)
+		}
+
+		if err := file.Save(t.char.FilePath); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nImported %d shortcut(s) into %s (%d)\n", len(bindings), t.name, t.char.CharacterID)
+		fmt.Printf("  Backup: %s\n", zipBackupPath)
+		for _, c := range settings.ShortcutConflicts(merged) {
+			fmt.Printf("  Warning: %s is bound to %s\n", c.Chord, strings.Join(c.Actions, ", "))
+		}
+	}
+
+	return nil
+}
+
+func runShortcutsConflicts(cmd *cobra.Command, args []string) error {
+	shortcuts, label, err := loadShortcuts(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	conflicts := settings.ShortcutConflicts(shortcuts)
+	if len(conflicts) == 0 {
+		fmt.Printf("No conflicting shortcuts found for %s.\n", label)
+		return nil
+	}
+
+	fmt.Printf("Conflicting shortcuts of %s:\n", label)
+	for _, c := range conflicts {
+		fmt.Printf("\n  %s\n", c.Chord)
+		for _, action := range c.Actions {
+			fmt.Printf("    - %s\n", action)
+		}
+	}
+
+	fmt.Printf("\n%d conflicting chord(s)\n", len(conflicts))
+	return nil
+}
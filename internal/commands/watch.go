@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/jpbriend/eve-settings-manager/internal/backup"
+	"github.com/jpbriend/eve-settings-manager/internal/esi"
+	"github.com/jpbriend/eve-settings-manager/internal/eve"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchSnapshot    bool
+	watchSnapshotDir string
+	watchSettle      time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Log settings files as the Eve client writes them",
+	Long: `Watch all detected settings folders and log every write of a character
+(core_char_*.dat) or account user (core_user_*.dat) settings file, with the
+character name and the change in size. Stop with Ctrl+C.
+
+The client writes settings when a character logs off and when it exits; once
+a write has been logged for a character, its settings are safe to copy.
+
+Use --snapshot to save a ZIP backup of every written file, which can be
+restored with 'esm restore'. Snapshots go to the esm folder of your user
+configuration directory unless --snapshot-dir is given.`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchSnapshot, "snapshot", false, "Save a backup ZIP of every written file")
+	watchCmd.Flags().StringVar(&watchSnapshotDir, "snapshot-dir", "", "Folder for snapshots (implies --snapshot)")
+	watchCmd.Flags().DurationVar(&watchSettle, "settle", time.Second, "Wait this long after the last write before logging a file")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	dirs, err := detectSettingsDirectories()
+	if err != nil {
+		return err
+	}
+
+	snapshotDir, err := resolveSnapshotDir()
+	if err != nil {
+		return err
+	}
+
+	watcher, err := eve.NewWatcher(dirs, watchSettle)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Watching %d settings folder(s):\n", len(dirs))
+	for _, dir := range dirs {
+		fmt.Printf("  %s\n", dir)
+	}
+	if snapshotDir != "" {
+		fmt.Printf("Saving snapshots to %s\n", snapshotDir)
+	}
+	fmt.Println("Press Ctrl+C to stop.")
+	fmt.Println()
+
+	esiClient := newESIClient()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("\nStopped watching.")
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+
+		case change, ok := <-watcher.Changes:
+			if !ok {
+				return nil
+			}
+			fmt.Println(describeChange(ctx, esiClient, change))
+
+			if snapshotDir != "" && !change.Removed {
+				path, err := snapshotChange(ctx, esiClient, snapshotDir, change)
+				if err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "Warning: failed to save snapshot: %v\n", err)
+				} else {
+					fmt.Printf("  snapshot: %s\n", path)
+				}
+			}
+		}
+	}
+}
+
+// resolveSnapshotDir returns the folder for snapshots, creating it, or "" if
+// snapshots are off.
+func resolveSnapshotDir() (string, error) {
+	dir := watchSnapshotDir
+	if dir == "" {
+		if !watchSnapshot {
+			return "", nil
+		}
+		var err error
+		if dir, err = defaultSnapshotDir(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return dir, nil
+}
+
+// defaultSnapshotDir returns the folder for snapshots in the user's
+// configuration directory.
+func defaultSnapshotDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "esm", "snapshots"), nil
+}
+
+// describeChange formats a logged settings change, e.g.
+// "17:40:02  John Capsuleer (123)  core_char_123.dat  12.1 KB (+120 bytes)  ...".
+func describeChange(ctx context.Context, esiClient *esi.Client, c eve.SettingsChange) string {
+	who := fmt.Sprintf("account user %d", c.UserID)
+	if c.CharacterID != 0 {
+		who = fmt.Sprintf("%s (%d)", esiClient.GetCharacterNameOrFallback(ctx, c.CharacterID), c.CharacterID)
+	}
+
+	var what string
+	switch {
+	case c.Removed:
+		what = "removed"
+	case c.PrevSize < 0:
+		what = fmt.Sprintf("%s (new)", formatSize(c.Size))
+	default:
+		what = fmt.Sprintf("%s (%+d bytes)", formatSize(c.Size), c.Size-c.PrevSize)
+	}
+
+	return fmt.Sprintf("%s  %s  %s  %s  %s", c.Time.Format("15:04:05"), who, filepath.Base(c.Path), what,
+		settingsLocation(c.Path))
+}
+
+// snapshotChange saves a ZIP backup of a written settings file and returns
+// its path.
+func snapshotChange(ctx context.Context, esiClient *esi.Client, dir string, c eve.SettingsChange) (string, error) {
+	stamp := c.Time.Format("20060102_150405")
+
+	if c.CharacterID != 0 {
+		path := filepath.Join(dir, fmt.Sprintf("backup_%d_%s.zip", c.CharacterID, stamp))
+		chars := []backup.CharacterBackup{{
+			CharacterID:   c.CharacterID,
+			CharacterName: esiClient.GetCharacterNameOrFallback(ctx, c.CharacterID),
+			OriginalPath:  c.Path,
+			FileName:      filepath.Base(c.Path),
+		}}
+		return path, backup.CreateBackup(path, chars, map[int64]string{c.CharacterID: c.Path})
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("backup_user_%d_%s.zip", c.UserID, stamp))
+	users := []backup.UserBackup{{
+		UserID:       c.UserID,
+		OriginalPath: c.Path,
+		FileName:     filepath.Base(c.Path),
+	}}
+	return path, backup.CreateBackupWithUsers(path, nil, nil, users, map[int64]string{c.UserID: c.Path})
+}
@@ -0,0 +1,154 @@
+// Package config loads the esm configuration file.
+//
+// The file lives in the user's configuration directory (config.toml under
+// ~/.config/esm on Linux, %AppData%\esm on Windows, ~/Library/Application
+// Support/esm on macOS) and is optional.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvSettingsRoot names the environment variable holding extra settings
+// roots, separated like PATH entries.
+const EnvSettingsRoot = "ESM_SETTINGS_ROOT"
+
+// Config is the contents of the configuration file.
+type Config struct {
+	// SettingsRoots are extra Eve settings base directories, i.e. folders
+	// containing installation folders such as c_eve_sharedcache_tq_tranquility.
+	SettingsRoots []string `toml:"settings_roots"`
+
+	// WinePrefixes are extra Wine prefixes with an Eve installation.
+	WinePrefixes []string `toml:"wine_prefixes"`
+
+	// Exclude lists directories that are never searched, with everything
+	// below them.
+	Exclude []string `toml:"exclude"`
+
+	// Offline stops esm from contacting ESI, as --offline does.
+	Offline bool `toml:"offline"`
+
+	// Aliases are names for characters, usable wherever a character name
+	// is expected. They also name characters ESI cannot be asked about.
+	Aliases map[string]int64 `toml:"aliases"`
+
+	// ESI configures how ESI is contacted.
+	ESI ESIConfig `toml:"esi"`
+}
+
+// ESIConfig is the [esi] table of the configuration file. Empty settings keep
+// the defaults.
+type ESIConfig struct {
+	// BaseURL is the address of ESI or of a mirror.
+	BaseURL string `toml:"base_url"`
+
+	// Datasource is the server ESI answers for: tranquility or singularity.
+	Datasource string `toml:"datasource"`
+
+	// UserAgent is sent with every request; CCP asks for contact details.
+	UserAgent string `toml:"user_agent"`
+}
+
+// Template is written by 'esm config edit' when no configuration file exists.
+const Template = `# esm configuration
+
+# Extra Eve settings folders, i.e. folders containing installation folders
+# such as c_eve_sharedcache_tq_tranquility.
+settings_roots = []
+
+# Extra Wine prefixes with an Eve installation.
+wine_prefixes = []
+
+# Folders that are never searched for settings.
+exclude = []
+
+# Never contact ESI; names then come from the name cache, backups and aliases.
+offline = false
+
+# Names for characters, usable wherever a character name is expected.
+[aliases]
+# main = 123456789
+
+[esi]
+# Address of ESI or of a mirror.
+# base_url = "https://esi.evetech.net/latest"
+
+# Server ESI answers for: tranquility or singularity. Defaults to singularity
+# with --server singularity, to tranquility otherwise.
+# datasource = "tranquility"
+
+# Sent with every request; CCP asks for contact details.
+# user_agent = "esm (you@example.com)"
+`
+
+// DefaultPath returns the location of the configuration file in the user's
+// configuration directory.
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "esm", "config.toml"), nil
+}
+
+// Load reads the configuration file at path. A missing file yields an empty
+// configuration. Leading ~ in paths is expanded to the home directory.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	meta, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown setting '%s' in config file %s", undecoded[0], path)
+	}
+
+	cfg.SettingsRoots = expandPaths(cfg.SettingsRoots)
+	cfg.WinePrefixes = expandPaths(cfg.WinePrefixes)
+	cfg.Exclude = expandPaths(cfg.Exclude)
+	return cfg, nil
+}
+
+// EnvRoots returns the settings roots listed in ESM_SETTINGS_ROOT.
+func EnvRoots() []string {
+	var roots []string
+	for _, root := range filepath.SplitList(os.Getenv(EnvSettingsRoot)) {
+		if root != "" {
+			roots = append(roots, root)
+		}
+	}
+	return expandPaths(roots)
+}
+
+// expandPaths replaces a leading ~ in each path with the home directory.
+func expandPaths(paths []string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return paths
+	}
+
+	expanded := make([]string, len(paths))
+	for i, path := range paths {
+		if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+			path = filepath.Join(home, path[1:])
+		}
+		expanded[i] = path
+	}
+	return expanded
+}
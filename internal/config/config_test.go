@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.SettingsRoots) != 0 || len(cfg.WinePrefixes) != 0 || len(cfg.Exclude) != 0 {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `settings_roots = ["/mnt/games/EVE", "~/eve"]
+wine_prefixes = ["~/Games/eve-online"]
+exclude = ["/mnt/games/EVE/c_old_install"]
+offline = true
+
+[aliases]
+main = 123456789
+"John Capsuleer" = 987654321
+
+[esi]
+base_url = "https://esi.example.com/latest"
+datasource = "singularity"
+user_agent = "esm (ops@example.com)"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.SettingsRoots) != 2 || cfg.SettingsRoots[1] != filepath.Join(home, "eve") {
+		t.Errorf("SettingsRoots = %v", cfg.SettingsRoots)
+	}
+	if len(cfg.WinePrefixes) != 1 || cfg.WinePrefixes[0] != filepath.Join(home, "Games", "eve-online") {
+		t.Errorf("WinePrefixes = %v", cfg.WinePrefixes)
+	}
+	if len(cfg.Exclude) != 1 || cfg.Exclude[0] != "/mnt/games/EVE/c_old_install" {
+		t.Errorf("Exclude = %v", cfg.Exclude)
+	}
+	if !cfg.Offline {
+		t.Error("Offline = false")
+	}
+	if len(cfg.Aliases) != 2 || cfg.Aliases["main"] != 123456789 || cfg.Aliases["John Capsuleer"] != 987654321 {
+		t.Errorf("Aliases = %v", cfg.Aliases)
+	}
+	want := ESIConfig{
+		BaseURL:    "https://esi.example.com/latest",
+		Datasource: "singularity",
+		UserAgent:  "esm (ops@example.com)",
+	}
+	if cfg.ESI != want {
+		t.Errorf("ESI = %+v", cfg.ESI)
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(Template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("template does not load: %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid":  "settings_roots = [",
+		"unknown":  "setting_roots = []",
+		"bad type": "exclude = 5",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEnvRoots(t *testing.T) {
+	t.Setenv(EnvSettingsRoot, "/a"+string(os.PathListSeparator)+string(os.PathListSeparator)+"/b")
+	roots := EnvRoots()
+	if len(roots) != 2 || roots[0] != "/a" || roots[1] != "/b" {
+		t.Errorf("EnvRoots() = %v", roots)
+	}
+}
@@ -0,0 +1,314 @@
+package esi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCharacterTTL is how long character information is kept when ESI
+	// does not send an Expires header.
+	defaultCharacterTTL = 24 * time.Hour
+
+	// nameTTL is how long a name to ID lookup is kept. The universe/ids
+	// endpoint does not send caching headers, and names rarely change.
+	nameTTL = 30 * 24 * time.Hour
+)
+
+// CachedCharacter is character information kept in the disk cache.
+type CachedCharacter struct {
+	Info    CharacterInfo `json:"info"`
+	ETag    string        `json:"etag,omitempty"`
+	Expires time.Time     `json:"expires"`
+
+	// NameOnly is set when only the name was resolved, in bulk, and the
+	// rest of Info may be missing or outdated.
+	NameOnly bool `json:"name_only,omitempty"`
+}
+
+// CachedName is a name to character ID lookup kept in the disk cache.
+type CachedName struct {
+	CharacterID int64     `json:"character_id"`
+	Expires     time.Time `json:"expires"`
+}
+
+// Cache persists ESI responses across runs. Entries stay in the cache after
+// they expire: an expired character is revalidated with its ETag, and is used
+// as it is when ESI cannot be reached.
+type Cache struct {
+	Characters map[int64]*CachedCharacter `json:"characters"`
+	Names      map[string]*CachedName     `json:"names"`
+
+	path  string
+	mu    sync.RWMutex
+	dirty bool
+}
+
+// CacheStats summarizes the contents of a cache.
+type CacheStats struct {
+	Characters        int
+	ExpiredCharacters int
+	Names             int
+	ExpiredNames      int
+}
+
+// DefaultCachePath returns the location of the ESI cache for datasource in
+// the user's cache directory. Each datasource but Tranquility, the default,
+// has its own cache.
+func DefaultCachePath(datasource string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+
+	name := "esi-cache.json"
+	if datasource != "" && datasource != DatasourceTranquility {
+		name = fmt.Sprintf("esi-cache-%s.json", datasource)
+	}
+	return filepath.Join(cacheDir, "esm", name), nil
+}
+
+// NewCache creates an empty cache that is saved to path.
+func NewCache(path string) *Cache {
+	return &Cache{
+		Characters: make(map[int64]*CachedCharacter),
+		Names:      make(map[string]*CachedName),
+		path:       path,
+	}
+}
+
+// LoadCache reads the cache at path.
+// A missing file yields an empty cache that will be created on Save.
+func LoadCache(path string) (*Cache, error) {
+	cache := NewCache(path)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ESI cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse ESI cache %s: %w", path, err)
+	}
+
+	if cache.Characters == nil {
+		cache.Characters = make(map[int64]*CachedCharacter)
+	}
+	if cache.Names == nil {
+		cache.Names = make(map[string]*CachedName)
+	}
+
+	return cache, nil
+}
+
+// Path returns the file the cache is saved to.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// Save writes the cache back to disk if it changed since it was loaded. The
+// file is replaced in one step, so that concurrent runs never read half of it.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create ESI cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode ESI cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".esi-cache-*")
+	if err != nil {
+		return fmt.Errorf("failed to write ESI cache: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write ESI cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write ESI cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write ESI cache: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// Stats counts the entries of the cache and how many of them expired by now.
+func (c *Cache) Stats(now time.Time) CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := CacheStats{Characters: len(c.Characters), Names: len(c.Names)}
+	for _, entry := range c.Characters {
+		if !now.Before(entry.Expires) {
+			stats.ExpiredCharacters++
+		}
+	}
+	for _, entry := range c.Names {
+		if !now.Before(entry.Expires) {
+			stats.ExpiredNames++
+		}
+	}
+	return stats
+}
+
+// Prune removes the entries that expired by now and returns how many were
+// removed.
+func (c *Cache) Prune(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for id, entry := range c.Characters {
+		if !now.Before(entry.Expires) {
+			delete(c.Characters, id)
+			removed++
+		}
+	}
+	for name, entry := range c.Names {
+		if !now.Before(entry.Expires) {
+			delete(c.Names, name)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		c.dirty = true
+	}
+	return removed
+}
+
+// character returns the cached information for a character, fresh or not.
+func (c *Cache) character(id int64) (*CachedCharacter, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.Characters[id]
+	return entry, ok
+}
+
+// putCharacter caches character information until expires.
+func (c *Cache) putCharacter(id int64, info CharacterInfo, etag string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Characters[id] = &CachedCharacter{Info: info, ETag: etag, Expires: expires}
+	c.dirty = true
+}
+
+// putCharacterName caches the name of a character resolved in bulk until
+// expires, keeping what else is known about the character.
+func (c *Cache) putCharacterName(id int64, name string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := CachedCharacter{}
+	if cached, ok := c.Characters[id]; ok {
+		entry = *cached
+	}
+	entry.Info.Name = name
+	entry.Expires = expires
+	entry.NameOnly = true
+
+	c.Characters[id] = &entry
+	c.dirty = true
+}
+
+// freshName returns the cached name of a character if it has not expired.
+func (c *Cache) freshName(id int64, now time.Time) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.Characters[id]
+	if !ok || !now.Before(entry.Expires) {
+		return "", false
+	}
+	return entry.Info.Name, true
+}
+
+// removeCharacter drops a character that ESI no longer knows.
+func (c *Cache) removeCharacter(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.Characters[id]; ok {
+		delete(c.Characters, id)
+		c.dirty = true
+	}
+}
+
+// name returns the character ID cached for name if it has not expired.
+func (c *Cache) name(name string, now time.Time) (int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.Names[name]
+	if !ok || !now.Before(entry.Expires) {
+		return 0, false
+	}
+	return entry.CharacterID, true
+}
+
+// lookupName returns the character ID of name from the name lookups or the
+// character information in the cache, whatever their age. Case is ignored.
+func (c *Cache) lookupName(name string) (int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if entry, ok := c.Names[name]; ok {
+		return entry.CharacterID, true
+	}
+	for cached, entry := range c.Names {
+		if strings.EqualFold(cached, name) {
+			return entry.CharacterID, true
+		}
+	}
+	for id, entry := range c.Characters {
+		if strings.EqualFold(entry.Info.Name, name) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// putName caches a name to character ID lookup.
+func (c *Cache) putName(name string, id int64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Names[name] = &CachedName{CharacterID: id, Expires: now.Add(nameTTL)}
+	c.dirty = true
+}
+
+// expiresAt returns the expiry time given by the Expires header of resp, or
+// now plus fallback if there is none.
+func expiresAt(resp *http.Response, now time.Time, fallback time.Duration) time.Time {
+	if expires, err := http.ParseTime(resp.Header.Get("Expires")); err == nil {
+		return expires
+	}
+	return now.Add(fallback)
+}
@@ -0,0 +1,188 @@
+package esi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "esm", "esi-cache.json")
+	expires := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	cache, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache of missing file failed: %v", err)
+	}
+	cache.putCharacter(123, CharacterInfo{Name: "John Capsuleer"}, `"abc"`, expires)
+	cache.putName("John Capsuleer", 123, expires)
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	entry, ok := loaded.character(123)
+	if !ok || entry.Info.Name != "John Capsuleer" || entry.ETag != `"abc"` || !entry.Expires.Equal(expires) {
+		t.Errorf("unexpected cached character %+v", entry)
+	}
+	if id, ok := loaded.name("John Capsuleer", expires); !ok || id != 123 {
+		t.Errorf("expected cached name for 123, got %d, %v", id, ok)
+	}
+}
+
+func TestDefaultCachePath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tq, err := DefaultCachePath("")
+	if err != nil {
+		t.Fatalf("DefaultCachePath failed: %v", err)
+	}
+	if explicit, _ := DefaultCachePath(DatasourceTranquility); explicit != tq {
+		t.Errorf("expected the same cache for tranquility, got %s and %s", tq, explicit)
+	}
+	if sisi, _ := DefaultCachePath(DatasourceSingularity); sisi == tq || filepath.Base(sisi) != "esi-cache-singularity.json" {
+		t.Errorf("expected a separate cache for singularity, got %s", sisi)
+	}
+}
+
+func TestCacheSaveUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "esi-cache.json")
+
+	if err := NewCache(path).Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected an unchanged cache not to be written, got %v", err)
+	}
+}
+
+func TestLoadCacheCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "esi-cache.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCache(path); err == nil {
+		t.Error("expected an error for a corrupt cache")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := NewCache("")
+	cache.putCharacter(1, CharacterInfo{Name: "Fresh"}, "", now.Add(time.Hour))
+	cache.putCharacter(2, CharacterInfo{Name: "Expired"}, "", now.Add(-time.Hour))
+	cache.putName("Fresh", 1, now)
+
+	stats := cache.Stats(now)
+	if stats != (CacheStats{Characters: 2, ExpiredCharacters: 1, Names: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if removed := cache.Prune(now); removed != 1 {
+		t.Errorf("expected 1 entry pruned, got %d", removed)
+	}
+	if _, ok := cache.character(2); ok {
+		t.Error("expected expired character to be pruned")
+	}
+	if _, ok := cache.character(1); !ok {
+		t.Error("expected fresh character to be kept")
+	}
+}
+
+func TestGetCharacterDiskCache(t *testing.T) {
+	var requests atomic.Int32
+	var lastIfNoneMatch atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		lastIfNoneMatch.Store(r.Header.Get("If-None-Match"))
+
+		switch r.URL.Path {
+		case "/characters/1/":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+			_, _ = w.Write([]byte(`{"name": "John Capsuleer"}`))
+		case "/characters/2/":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newClient := func(cache *Cache) *Client {
+		return NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCache(cache))
+	}
+
+	cache := NewCache("")
+
+	t.Run("fetch stores ETag and Expires", func(t *testing.T) {
+		name, err := newClient(cache).GetCharacterName(context.Background(), 1)
+		if err != nil || name != "John Capsuleer" {
+			t.Fatalf("expected John Capsuleer, got %q, %v", name, err)
+		}
+		entry, ok := cache.character(1)
+		if !ok || entry.ETag != `"v1"` || !entry.Expires.After(time.Now()) {
+			t.Errorf("unexpected cache entry %+v", entry)
+		}
+	})
+
+	t.Run("fresh entry is used without a request", func(t *testing.T) {
+		before := requests.Load()
+		if _, err := newClient(cache).GetCharacter(context.Background(), 1); err != nil {
+			t.Fatalf("GetCharacter failed: %v", err)
+		}
+		if requests.Load() != before {
+			t.Error("expected no request for a fresh entry")
+		}
+	})
+
+	t.Run("expired entry is revalidated", func(t *testing.T) {
+		cache.Characters[1].Expires = time.Now().Add(-time.Minute)
+
+		name, err := newClient(cache).GetCharacterName(context.Background(), 1)
+		if err != nil || name != "John Capsuleer" {
+			t.Fatalf("expected John Capsuleer, got %q, %v", name, err)
+		}
+		if got := lastIfNoneMatch.Load(); got != `"v1"` {
+			t.Errorf("expected If-None-Match \"v1\", got %v", got)
+		}
+		if !cache.Characters[1].Expires.After(time.Now()) {
+			t.Error("expected 304 to extend the expiry")
+		}
+	})
+
+	t.Run("stale entry is used when ESI fails", func(t *testing.T) {
+		cache.putCharacter(2, CharacterInfo{Name: "Stale Name"}, "", time.Now().Add(-time.Hour))
+
+		name, err := newClient(cache).GetCharacterName(context.Background(), 2)
+		if err != nil || name != "Stale Name" {
+			t.Errorf("expected stale name, got %q, %v", name, err)
+		}
+	})
+
+	t.Run("unknown character is removed", func(t *testing.T) {
+		cache.putCharacter(3, CharacterInfo{Name: "Biomassed"}, "", time.Now().Add(-time.Hour))
+
+		if _, err := newClient(cache).GetCharacter(context.Background(), 3); err == nil {
+			t.Error("expected an error for an unknown character")
+		}
+		if _, ok := cache.character(3); ok {
+			t.Error("expected unknown character to be removed from the cache")
+		}
+	})
+}
@@ -2,16 +2,29 @@ package esi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	baseURL        = "https://esi.evetech.net/latest"
-	requestTimeout = 10 * time.Second
+	defaultBaseURL   = "https://esi.evetech.net/latest"
+	defaultUserAgent = "eve-settings-manager (+https://github.com/jpbriend/eve-settings-manager)"
+	requestTimeout   = 10 * time.Second
+)
+
+// Datasources served by ESI.
+const (
+	DatasourceTranquility = "tranquility"
+	DatasourceSingularity = "singularity"
 )
 
 // CharacterInfo represents public character information from ESI.
@@ -33,27 +46,147 @@ type UniverseIDsResult struct {
 	} `json:"characters"`
 }
 
+// ServerStatus represents the response from the ESI status endpoint.
+type ServerStatus struct {
+	Players       int    `json:"players"`
+	ServerVersion string `json:"server_version"`
+	StartTime     string `json:"start_time"`
+	VIP           bool   `json:"vip"`
+}
+
+// ErrOffline is returned for lookups that need ESI when it is not used,
+// either because the client is offline or because ESI could not be reached
+// earlier.
+var ErrOffline = errors.New("ESI is not available (offline)")
+
 // Client is an ESI API client with caching.
+//
+// Names are resolved from ESI, then from the disk cache and from the names
+// given with WithAliases and WithFallbackNames. Once ESI cannot be reached,
+// the client stops contacting it.
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	datasource string
+	userAgent  string
 	cache      map[int64]*CharacterInfo
+	names      map[int64]string // character ID -> name, from bulk lookups
 	nameCache  map[string]int64 // name -> character ID cache
 	cacheMu    sync.RWMutex
+	disk       *Cache // optional, kept across runs
+
+	offline     bool
+	unreachable atomic.Bool
+	aliases     map[string]int64 // lower-case name -> character ID
+
+	fallbackOnce sync.Once
+	loadFallback func() map[int64]string
+	fallback     map[int64]string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL makes the client use the ESI at baseURL, e.g. a mirror, instead
+// of https://esi.evetech.net/latest.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithDatasource selects the server ESI answers for (tranquility or
+// singularity). By default ESI answers for Tranquility.
+func WithDatasource(datasource string) Option {
+	return func(c *Client) {
+		c.datasource = datasource
+	}
+}
+
+// WithUserAgent sets the User-Agent of requests. CCP asks for it to hold
+// contact information, such as an email address.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHTTPClient makes the client send requests with httpClient. Its
+// transport is wrapped to retry failed requests and respect the ESI error
+// limit.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithCache makes the client look up and store responses in cache, so that
+// they are kept across runs. Saving the cache is up to the caller.
+func WithCache(cache *Cache) Option {
+	return func(c *Client) {
+		c.disk = cache
+	}
+}
+
+// WithOffline makes the client resolve names only from its caches, aliases
+// and fallback names, without contacting ESI.
+func WithOffline(offline bool) Option {
+	return func(c *Client) {
+		c.offline = offline
+	}
+}
+
+// WithAliases sets names that resolve to character IDs. Aliases take
+// precedence over ESI, and are matched regardless of case.
+func WithAliases(aliases map[string]int64) Option {
+	return func(c *Client) {
+		c.aliases = make(map[string]int64, len(aliases))
+		for name, id := range aliases {
+			c.aliases[strings.ToLower(name)] = id
+		}
+	}
+}
+
+// WithFallbackNames sets a function returning character names to use when
+// neither ESI nor the cache knows a character. It is called once, the first
+// time it is needed.
+func WithFallbackNames(load func() map[int64]string) Option {
+	return func(c *Client) {
+		c.loadFallback = load
+	}
 }
 
 // NewClient creates a new ESI API client.
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: requestTimeout,
 		},
+		baseURL:   defaultBaseURL,
+		userAgent: defaultUserAgent,
 		cache:     make(map[int64]*CharacterInfo),
+		names:     make(map[int64]string),
 		nameCache: make(map[string]int64),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Requests go through the retry layer, whichever HTTP client is used, and
+	// share the error budget with the other clients
+	httpClient := *c.httpClient
+	httpClient.Transport = newRetryTransport(httpClient.Transport, sharedErrorBudget)
+	c.httpClient = &httpClient
+	return c
+}
+
+// Online reports whether the client still contacts ESI.
+func (c *Client) Online() bool {
+	return !c.offline && !c.unreachable.Load()
 }
 
 // GetCharacter fetches public character information by ID.
-func (c *Client) GetCharacter(characterID int64) (*CharacterInfo, error) {
+func (c *Client) GetCharacter(ctx context.Context, characterID int64) (*CharacterInfo, error) {
 	// Check cache first
 	c.cacheMu.RLock()
 	if info, ok := c.cache[characterID]; ok {
@@ -62,9 +195,55 @@ func (c *Client) GetCharacter(characterID int64) (*CharacterInfo, error) {
 	}
 	c.cacheMu.RUnlock()
 
-	// Fetch from API
-	url := fmt.Sprintf("%s/characters/%d/", baseURL, characterID)
-	resp, err := c.httpClient.Get(url)
+	// Then the disk cache; expired entries are revalidated below
+	now := time.Now()
+	var cached *CachedCharacter
+	if c.disk != nil {
+		if entry, ok := c.disk.character(characterID); ok {
+			if now.Before(entry.Expires) && !entry.NameOnly {
+				return c.remember(characterID, entry.Info), nil
+			}
+			cached = entry
+		}
+	}
+
+	err := fmt.Errorf("character %d: %w", characterID, ErrOffline)
+	if c.Online() {
+		var info *CharacterInfo
+		if info, err = c.fetchCharacter(ctx, characterID, cached, now); err == nil {
+			return c.remember(characterID, *info), nil
+		}
+		if errors.Is(err, errCharacterNotFound) || ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	// A stale or local name is better than none when ESI cannot be used
+	if cached != nil {
+		return c.remember(characterID, cached.Info), nil
+	}
+	if name, ok := c.localName(characterID); ok {
+		return c.remember(characterID, CharacterInfo{Name: name}), nil
+	}
+	return nil, err
+}
+
+// errCharacterNotFound is wrapped in the errors for characters ESI does not know.
+var errCharacterNotFound = errors.New("not found")
+
+// fetchCharacter requests character information from ESI, as a conditional
+// request if cached holds an ETag, and updates the disk cache.
+func (c *Client) fetchCharacter(ctx context.Context, characterID int64, cached *CachedCharacter,
+	now time.Time) (*CharacterInfo, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("/characters/%d/", characterID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch character %d: %w", characterID, err)
 	}
@@ -72,11 +251,18 @@ func (c *Client) GetCharacter(characterID int64) (*CharacterInfo, error) {
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("character %d not found", characterID)
-	}
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		c.disk.putCharacter(characterID, cached.Info, cached.ETag, expiresAt(resp, now, defaultCharacterTTL))
+		return &cached.Info, nil
 
-	if resp.StatusCode != http.StatusOK {
+	case resp.StatusCode == http.StatusNotFound:
+		if c.disk != nil {
+			c.disk.removeCharacter(characterID)
+		}
+		return nil, fmt.Errorf("character %d %w", characterID, errCharacterNotFound)
+
+	case resp.StatusCode != http.StatusOK:
 		return nil, fmt.Errorf("ESI API returned status %d for character %d", resp.StatusCode, characterID)
 	}
 
@@ -85,17 +271,129 @@ func (c *Client) GetCharacter(characterID int64) (*CharacterInfo, error) {
 		return nil, fmt.Errorf("failed to decode character info: %w", err)
 	}
 
-	// Cache the result
+	if c.disk != nil {
+		c.disk.putCharacter(characterID, info, resp.Header.Get("ETag"), expiresAt(resp, now, defaultCharacterTTL))
+	}
+	return &info, nil
+}
+
+// newRequest creates a request for the ESI endpoint at path, for the
+// client's datasource and with its User-Agent. A body is sent as JSON.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	endpoint := c.baseURL + path
+	if c.datasource != "" {
+		endpoint += "?datasource=" + url.QueryEscape(c.datasource)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// do sends a request to ESI. A failure to reach ESI turns the client offline
+// for the remaining lookups, so that each of them does not wait for a timeout.
+// A cancelled request does not.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil && req.Context().Err() == nil {
+		c.unreachable.Store(true)
+	}
+	return resp, err
+}
+
+// localName returns the name of a character known from the fallback names or
+// the aliases.
+func (c *Client) localName(characterID int64) (string, bool) {
+	if name, ok := c.fallbackNames()[characterID]; ok {
+		return name, true
+	}
+	for name, id := range c.aliases {
+		if id == characterID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// localID returns the ID of the character called name according to the disk
+// cache, whatever its age, or the fallback names. Case is ignored.
+func (c *Client) localID(name string) (int64, bool) {
+	if c.disk != nil {
+		if id, ok := c.disk.lookupName(name); ok {
+			return id, true
+		}
+	}
+	for id, known := range c.fallbackNames() {
+		if strings.EqualFold(known, name) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// fallbackNames loads the fallback names on first use.
+func (c *Client) fallbackNames() map[int64]string {
+	c.fallbackOnce.Do(func() {
+		if c.loadFallback != nil {
+			c.fallback = c.loadFallback()
+		}
+	})
+	return c.fallback
+}
+
+// remember keeps character information in the in-memory cache.
+func (c *Client) remember(characterID int64, info CharacterInfo) *CharacterInfo {
 	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
 	c.cache[characterID] = &info
-	c.cacheMu.Unlock()
+	return &info
+}
 
-	return &info, nil
+// GetStatus fetches the status of the server of the client's datasource. It
+// is a cheap way to check that ESI can be reached.
+func (c *Client) GetStatus(ctx context.Context) (*ServerStatus, error) {
+	if !c.Online() {
+		return nil, ErrOffline
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/status/", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch server status: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ESI API returned status %d for server status", resp.StatusCode)
+	}
+
+	var status ServerStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode server status: %w", err)
+	}
+	return &status, nil
 }
 
 // GetCharacterName is a convenience method to get just the character name.
-func (c *Client) GetCharacterName(characterID int64) (string, error) {
-	info, err := c.GetCharacter(characterID)
+func (c *Client) GetCharacterName(ctx context.Context, characterID int64) (string, error) {
+	if name, ok := c.cachedName(characterID, time.Now()); ok {
+		return name, nil
+	}
+
+	info, err := c.GetCharacter(ctx, characterID)
 	if err != nil {
 		return "", err
 	}
@@ -103,43 +401,16 @@ func (c *Client) GetCharacterName(characterID int64) (string, error) {
 }
 
 // GetCharacterNameOrFallback returns the character name or a fallback string if lookup fails.
-func (c *Client) GetCharacterNameOrFallback(characterID int64) string {
-	name, err := c.GetCharacterName(characterID)
+func (c *Client) GetCharacterNameOrFallback(ctx context.Context, characterID int64) string {
+	name, err := c.GetCharacterName(ctx, characterID)
 	if err != nil {
 		return fmt.Sprintf("Unknown (%d)", characterID)
 	}
 	return name
 }
 
-// BatchGetCharacterNames fetches names for multiple character IDs concurrently.
-func (c *Client) BatchGetCharacterNames(characterIDs []int64) map[int64]string {
-	results := make(map[int64]string)
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	// Limit concurrency to avoid overwhelming the API
-	sem := make(chan struct{}, 5)
-
-	for _, id := range characterIDs {
-		wg.Add(1)
-		go func(charID int64) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			name := c.GetCharacterNameOrFallback(charID)
-			mu.Lock()
-			results[charID] = name
-			mu.Unlock()
-		}(id)
-	}
-
-	wg.Wait()
-	return results
-}
-
 // SearchCharacterByName searches for a character by exact name and returns their ID.
-func (c *Client) SearchCharacterByName(name string) (int64, error) {
+func (c *Client) SearchCharacterByName(ctx context.Context, name string) (int64, error) {
 	// Check name cache first
 	c.cacheMu.RLock()
 	if id, ok := c.nameCache[name]; ok {
@@ -148,17 +419,45 @@ func (c *Client) SearchCharacterByName(name string) (int64, error) {
 	}
 	c.cacheMu.RUnlock()
 
+	if id, ok := c.aliases[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+	if c.disk != nil {
+		if id, ok := c.disk.name(name, time.Now()); ok {
+			return id, nil
+		}
+	}
+
+	err := fmt.Errorf("character '%s' is not in the cache, backups or aliases: %w", name, ErrOffline)
+	if c.Online() {
+		var charID int64
+		charID, err = c.searchCharacter(ctx, name)
+		if err == nil || errors.Is(err, errCharacterNotFound) || ctx.Err() != nil {
+			return charID, err
+		}
+	}
+
+	// Fall back to names known locally when ESI cannot be used
+	if id, ok := c.localID(name); ok {
+		return id, nil
+	}
+	return 0, err
+}
+
+// searchCharacter resolves a character name with ESI.
+func (c *Client) searchCharacter(ctx context.Context, name string) (int64, error) {
 	// Use POST /universe/ids/ to resolve name to ID
 	requestBody, err := json.Marshal([]string{name})
 	if err != nil {
 		return 0, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		baseURL+"/universe/ids/",
-		"application/json",
-		bytes.NewReader(requestBody),
-	)
+	req, err := c.newRequest(ctx, http.MethodPost, "/universe/ids/", bytes.NewReader(requestBody))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to search for character '%s': %w", name, err)
 	}
@@ -176,7 +475,7 @@ func (c *Client) SearchCharacterByName(name string) (int64, error) {
 	}
 
 	if len(result.Characters) == 0 {
-		return 0, fmt.Errorf("character '%s' not found", name)
+		return 0, fmt.Errorf("character '%s' %w", name, errCharacterNotFound)
 	}
 
 	charID := result.Characters[0].ID
@@ -185,13 +484,16 @@ func (c *Client) SearchCharacterByName(name string) (int64, error) {
 	c.cacheMu.Lock()
 	c.nameCache[name] = charID
 	c.cacheMu.Unlock()
+	if c.disk != nil {
+		c.disk.putName(name, charID, time.Now())
+	}
 
 	return charID, nil
 }
 
 // ResolveCharacter resolves a character identifier (ID or name) to a character ID.
 // If the input is numeric, it's treated as an ID. Otherwise, it's searched by name.
-func (c *Client) ResolveCharacter(identifier string) (int64, error) {
+func (c *Client) ResolveCharacter(ctx context.Context, identifier string) (int64, error) {
 	// Try to parse as ID first
 	var id int64
 	_, err := fmt.Sscanf(identifier, "%d", &id)
@@ -200,5 +502,5 @@ func (c *Client) ResolveCharacter(identifier string) (int64, error) {
 	}
 
 	// Not a number, search by name
-	return c.SearchCharacterByName(identifier)
+	return c.SearchCharacterByName(ctx, identifier)
 }
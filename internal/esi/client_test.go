@@ -1,15 +1,32 @@
 package esi
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+// newTestServer starts a stand-in for ESI serving handler, and returns a
+// client using it.
+func newTestServer(t *testing.T, handler http.HandlerFunc, opts ...Option) (*httptest.Server, *Client) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithBaseURL(server.URL), WithHTTPClient(server.Client())}, opts...)
+	return server, NewClient(opts...)
+}
+
 func TestGetCharacter(t *testing.T) {
-	// Create mock server
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/characters/12345/" {
+	var requests atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method == http.MethodGet && r.URL.Path == "/characters/12345/" {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte(`{
@@ -23,27 +40,10 @@ func TestGetCharacter(t *testing.T) {
 		} else {
 			w.WriteHeader(http.StatusNotFound)
 		}
-	}))
-	defer server.Close()
-
-	// Create client with mock server
-	client := &Client{
-		httpClient: server.Client(),
-		cache:      make(map[int64]*CharacterInfo),
-	}
+	})
 
-	// Override base URL for testing (we'll test the actual URL construction separately)
-	// For this test, we'll use the mock server directly
 	t.Run("successful fetch", func(t *testing.T) {
-		// Note: This tests the caching and response parsing logic
-		// The actual HTTP call would go to the real ESI in production
-		info := &CharacterInfo{
-			Name:          "Test Character",
-			CorporationID: 98000001,
-		}
-		client.cache[12345] = info
-
-		result, err := client.GetCharacter(12345)
+		result, err := client.GetCharacter(context.Background(), 12345)
 		if err != nil {
 			t.Fatalf("GetCharacter failed: %v", err)
 		}
@@ -51,42 +51,53 @@ func TestGetCharacter(t *testing.T) {
 		if result.Name != "Test Character" {
 			t.Errorf("expected name 'Test Character', got '%s'", result.Name)
 		}
+		if result.CorporationID != 98000001 || result.Gender != "male" {
+			t.Errorf("unexpected character info %+v", result)
+		}
 	})
 
 	t.Run("cache hit", func(t *testing.T) {
-		// Pre-populate cache
-		client.cache[99999] = &CharacterInfo{Name: "Cached Character"}
-
-		result, err := client.GetCharacter(99999)
+		before := requests.Load()
+		result, err := client.GetCharacter(context.Background(), 12345)
 		if err != nil {
 			t.Fatalf("GetCharacter failed: %v", err)
 		}
 
-		if result.Name != "Cached Character" {
+		if result.Name != "Test Character" {
 			t.Errorf("expected cached result, got '%s'", result.Name)
 		}
+		if requests.Load() != before {
+			t.Error("expected no request for a cached character")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := client.GetCharacter(context.Background(), 99999)
+		if err == nil || err.Error() != "character 99999 not found" {
+			t.Errorf("expected not found error, got %v", err)
+		}
 	})
 }
 
 func TestGetCharacterNameOrFallback(t *testing.T) {
-	client := NewClient()
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
 
 	// Pre-populate cache
 	client.cache[12345] = &CharacterInfo{Name: "Known Character"}
 
 	t.Run("known character", func(t *testing.T) {
-		name := client.GetCharacterNameOrFallback(12345)
+		name := client.GetCharacterNameOrFallback(context.Background(), 12345)
 		if name != "Known Character" {
 			t.Errorf("expected 'Known Character', got '%s'", name)
 		}
 	})
 
 	t.Run("unknown character returns fallback", func(t *testing.T) {
-		// This will fail the API call and return fallback
-		// In a real test we'd mock the HTTP client
-		name := client.GetCharacterNameOrFallback(99999999999)
-		if name == "" {
-			t.Error("expected non-empty fallback string")
+		name := client.GetCharacterNameOrFallback(context.Background(), 99999999999)
+		if name != "Unknown (99999999999)" {
+			t.Errorf("expected 'Unknown (99999999999)', got '%s'", name)
 		}
 	})
 }
@@ -99,7 +110,7 @@ func TestBatchGetCharacterNames(t *testing.T) {
 	client.cache[222] = &CharacterInfo{Name: "Char Two"}
 
 	ids := []int64{111, 222}
-	results := client.BatchGetCharacterNames(ids)
+	results := client.BatchGetCharacterNames(context.Background(), ids)
 
 	if len(results) != 2 {
 		t.Errorf("expected 2 results, got %d", len(results))
@@ -138,7 +149,7 @@ func TestResolveCharacter(t *testing.T) {
 	client := NewClient()
 
 	t.Run("numeric ID", func(t *testing.T) {
-		id, err := client.ResolveCharacter("12345678")
+		id, err := client.ResolveCharacter(context.Background(), "12345678")
 		if err != nil {
 			t.Fatalf("ResolveCharacter failed: %v", err)
 		}
@@ -148,14 +159,14 @@ func TestResolveCharacter(t *testing.T) {
 	})
 
 	t.Run("zero is invalid", func(t *testing.T) {
-		_, err := client.ResolveCharacter("0")
+		_, err := client.ResolveCharacter(context.Background(), "0")
 		if err == nil {
 			t.Error("expected error for zero ID")
 		}
 	})
 
 	t.Run("negative is invalid", func(t *testing.T) {
-		_, err := client.ResolveCharacter("-123")
+		_, err := client.ResolveCharacter(context.Background(), "-123")
 		if err == nil {
 			t.Error("expected error for negative ID")
 		}
@@ -168,7 +179,7 @@ func TestSearchCharacterByName_Cache(t *testing.T) {
 	// Pre-populate name cache
 	client.nameCache["CCP Falcon"] = 92532650
 
-	id, err := client.SearchCharacterByName("CCP Falcon")
+	id, err := client.SearchCharacterByName(context.Background(), "CCP Falcon")
 	if err != nil {
 		t.Fatalf("SearchCharacterByName failed: %v", err)
 	}
@@ -177,3 +188,162 @@ func TestSearchCharacterByName_Cache(t *testing.T) {
 		t.Errorf("expected 92532650, got %d", id)
 	}
 }
+
+func TestSearchCharacterByName(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var names []string
+		if r.Method != http.MethodPost || r.URL.Path != "/universe/ids/" ||
+			r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&names) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(names) == 1 && names[0] == "CCP Falcon" {
+			_, _ = w.Write([]byte(`{"characters": [{"id": 92532650, "name": "CCP Falcon"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	id, err := client.ResolveCharacter(context.Background(), "CCP Falcon")
+	if err != nil || id != 92532650 {
+		t.Errorf("expected 92532650, got %d, %v", id, err)
+	}
+
+	if _, err := client.ResolveCharacter(context.Background(), "Nobody Atall"); err == nil || err.Error() != "character 'Nobody Atall' not found" {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"players": 21345, "server_version": "2931337", "start_time": "2026-10-16T11:02:00Z"}`))
+	})
+
+	status, err := client.GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Players != 21345 || status.ServerVersion != "2931337" {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	var query, userAgent atomic.Value
+	server, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query.Store(r.URL.RawQuery)
+		userAgent.Store(r.Header.Get("User-Agent"))
+		_, _ = w.Write([]byte(`{"players": 1}`))
+	}, WithDatasource(DatasourceSingularity), WithUserAgent("esm-test (ops@example.com)"))
+
+	if _, err := client.GetStatus(context.Background()); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if got := query.Load(); got != "datasource=singularity" {
+		t.Errorf("expected datasource=singularity, got %q", got)
+	}
+	if got := userAgent.Load(); got != "esm-test (ops@example.com)" {
+		t.Errorf("expected custom User-Agent, got %q", got)
+	}
+
+	// Defaults, and a base URL given with a trailing slash
+	client = NewClient(WithBaseURL(server.URL+"/"), WithHTTPClient(server.Client()))
+	if _, err := client.GetStatus(context.Background()); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if got := query.Load(); got != "" {
+		t.Errorf("expected no datasource by default, got %q", got)
+	}
+	if got := userAgent.Load(); got != defaultUserAgent {
+		t.Errorf("expected default User-Agent, got %q", got)
+	}
+}
+
+// failingTransport fails every request as if ESI could not be reached.
+type failingTransport struct {
+	requests *atomic.Int32
+}
+
+func (ft failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ft.requests.Add(1)
+	return nil, errors.New("network is unreachable")
+}
+
+func TestOfflineResolution(t *testing.T) {
+	var requests atomic.Int32
+	var loads atomic.Int32
+
+	cache := NewCache("")
+	cache.putCharacter(1, CharacterInfo{Name: "John Capsuleer"}, "", time.Now().Add(-time.Hour))
+
+	client := NewClient(
+		WithHTTPClient(&http.Client{Transport: failingTransport{requests: &requests}}),
+		WithCache(cache),
+		WithOffline(true),
+		WithAliases(map[string]int64{"Main": 7}),
+		WithFallbackNames(func() map[int64]string {
+			loads.Add(1)
+			return map[int64]string{9: "Backup Pilot"}
+		}),
+	)
+
+	names := map[int64]string{1: "John Capsuleer", 9: "Backup Pilot", 7: "main"}
+	for id, want := range names {
+		if got := client.GetCharacterNameOrFallback(context.Background(), id); got != want {
+			t.Errorf("name of %d: expected %q, got %q", id, want, got)
+		}
+	}
+
+	ids := map[string]int64{"john capsuleer": 1, "MAIN": 7, "Backup Pilot": 9}
+	for name, want := range ids {
+		if got, err := client.ResolveCharacter(context.Background(), name); err != nil || got != want {
+			t.Errorf("ID of %q: expected %d, got %d, %v", name, want, got, err)
+		}
+	}
+
+	if _, err := client.ResolveCharacter(context.Background(), "Nobody"); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline for an unknown name, got %v", err)
+	}
+	if _, err := client.GetStatus(context.Background()); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline from GetStatus, got %v", err)
+	}
+
+	if requests.Load() != 0 {
+		t.Errorf("expected no requests when offline, got %d", requests.Load())
+	}
+	if loads.Load() != 1 {
+		t.Errorf("expected fallback names to be loaded once, got %d", loads.Load())
+	}
+}
+
+func TestUnreachableFallsBack(t *testing.T) {
+	var requests atomic.Int32
+
+	client := NewClient(
+		WithHTTPClient(&http.Client{Transport: failingTransport{requests: &requests}}),
+		WithFallbackNames(func() map[int64]string {
+			return map[int64]string{9: "Backup Pilot"}
+		}),
+	)
+
+	if name := client.GetCharacterNameOrFallback(context.Background(), 9); name != "Backup Pilot" {
+		t.Errorf("expected fallback name, got %q", name)
+	}
+	if client.Online() {
+		t.Error("expected client to go offline after a network failure")
+	}
+
+	if id, err := client.ResolveCharacter(context.Background(), "backup pilot"); err != nil || id != 9 {
+		t.Errorf("expected 9, got %d, %v", id, err)
+	}
+	if name := client.GetCharacterNameOrFallback(context.Background(), 10); name != "Unknown (10)" {
+		t.Errorf("expected fallback string, got %q", name)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected a single request, got %d", requests.Load())
+	}
+}
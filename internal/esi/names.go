@@ -0,0 +1,228 @@
+package esi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// maxNamesPerRequest is the most IDs POST /universe/names/ accepts.
+	maxNamesPerRequest = 1000
+
+	// maxConcurrentRequests limits the per-ID requests made at once.
+	maxConcurrentRequests = 5
+
+	// maxNameSplits is how many times a batch may split a chunk rejected by
+	// POST /universe/names/. Each rejection counts against the ESI error
+	// limit, so once it is spent the IDs of rejected chunks are looked up one
+	// at a time instead.
+	maxNameSplits = 16
+
+	// minNameSplitSize is the size up to which a rejected chunk is looked up
+	// one ID at a time rather than split again.
+	minNameSplitSize = 8
+)
+
+// universeName is an entry of the response of POST /universe/names/.
+type universeName struct {
+	Category string `json:"category"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+}
+
+// BatchGetCharacterNames fetches names for multiple character IDs.
+//
+// Names not cached are resolved in bulk with POST /universe/names/, in chunks
+// of up to 1000 IDs. IDs that endpoint rejects, and all of them when it
+// cannot be used, are looked up one at a time as GetCharacterNameOrFallback
+// does.
+func (c *Client) BatchGetCharacterNames(ctx context.Context, characterIDs []int64) map[int64]string {
+	results := make(map[int64]string, len(characterIDs))
+
+	now := time.Now()
+	var pending []int64
+	seen := make(map[int64]bool, len(characterIDs))
+	for _, id := range characterIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if name, ok := c.cachedName(id, now); ok {
+			results[id] = name
+		} else {
+			pending = append(pending, id)
+		}
+	}
+
+	var perID []int64
+	splits := maxNameSplits
+	for start := 0; start < len(pending); start += maxNamesPerRequest {
+		chunk := pending[start:min(start+maxNamesPerRequest, len(pending))]
+		if !c.Online() {
+			perID = append(perID, chunk...)
+			continue
+		}
+
+		names, missing, err := c.resolveNames(ctx, chunk, &splits)
+		if err != nil {
+			// Look the chunk up one at a time, from the caches if ESI is gone
+			perID = append(perID, chunk...)
+			continue
+		}
+		for id, name := range names {
+			results[id] = name
+		}
+		perID = append(perID, missing...)
+	}
+
+	for id, name := range c.getNamesPerID(ctx, perID) {
+		results[id] = name
+	}
+	return results
+}
+
+// resolveNames resolves the names of characters with POST /universe/names/.
+// ESI rejects a request holding any unknown ID, so a rejected request is
+// split in halves, while splits remain, to narrow down the IDs to blame. The
+// IDs of small or unsplit rejected requests are returned as missing, with the
+// IDs that are not characters.
+func (c *Client) resolveNames(ctx context.Context, ids []int64, splits *int) (map[int64]string, []int64, error) {
+	requestBody, err := json.Marshal(ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/universe/names/", bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	now := time.Now()
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve %d character name(s): %w", len(ids), err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		if len(ids) <= minNameSplitSize || *splits == 0 {
+			return map[int64]string{}, ids, nil
+		}
+		*splits--
+		return c.resolveHalves(ctx, ids, splits)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("ESI universe/names returned status %d", resp.StatusCode)
+	}
+
+	var entries []universeName
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode names: %w", err)
+	}
+
+	expires := expiresAt(resp, now, defaultCharacterTTL)
+	names := make(map[int64]string, len(entries))
+	for _, entry := range entries {
+		if entry.Category != "character" {
+			continue
+		}
+		names[entry.ID] = entry.Name
+		c.rememberName(entry.ID, entry.Name, expires)
+	}
+
+	var missing []int64
+	for _, id := range ids {
+		if _, ok := names[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return names, missing, nil
+}
+
+// resolveHalves resolves the names of both halves of ids separately.
+func (c *Client) resolveHalves(ctx context.Context, ids []int64, splits *int) (map[int64]string, []int64, error) {
+	half := len(ids) / 2
+
+	names, missing, err := c.resolveNames(ctx, ids[:half], splits)
+	if err != nil {
+		return nil, nil, err
+	}
+	moreNames, moreMissing, err := c.resolveNames(ctx, ids[half:], splits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for id, name := range moreNames {
+		names[id] = name
+	}
+	return names, append(missing, moreMissing...), nil
+}
+
+// getNamesPerID looks up names one character at a time, a few at once.
+func (c *Client) getNamesPerID(ctx context.Context, characterIDs []int64) map[int64]string {
+	results := make(map[int64]string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	// Limit concurrency to avoid overwhelming the API
+	sem := make(chan struct{}, maxConcurrentRequests)
+
+	for _, id := range characterIDs {
+		wg.Add(1)
+		go func(charID int64) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			name := c.GetCharacterNameOrFallback(ctx, charID)
+			mu.Lock()
+			results[charID] = name
+			mu.Unlock()
+		}(id)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// cachedName returns the name of a character from the in-memory caches or,
+// if it has not expired, the disk cache.
+func (c *Client) cachedName(characterID int64, now time.Time) (string, bool) {
+	c.cacheMu.RLock()
+	if info, ok := c.cache[characterID]; ok {
+		c.cacheMu.RUnlock()
+		return info.Name, true
+	}
+	if name, ok := c.names[characterID]; ok {
+		c.cacheMu.RUnlock()
+		return name, true
+	}
+	c.cacheMu.RUnlock()
+
+	if c.disk != nil {
+		return c.disk.freshName(characterID, now)
+	}
+	return "", false
+}
+
+// rememberName keeps a name resolved in bulk in the caches.
+func (c *Client) rememberName(characterID int64, name string, expires time.Time) {
+	c.cacheMu.Lock()
+	c.names[characterID] = name
+	c.nameCache[name] = characterID
+	c.cacheMu.Unlock()
+
+	if c.disk != nil {
+		c.disk.putCharacterName(characterID, name, expires)
+		c.disk.putName(name, characterID, time.Now())
+	}
+}
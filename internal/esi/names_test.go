@@ -0,0 +1,241 @@
+package esi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// namesServer stands in for ESI, answering POST /universe/names/ and
+// GET /characters/{id}/ for the characters 1 to 1000000 except those in
+// unknown, after waiting latency. It counts the requests it rejects, which
+// ESI counts against its error limit.
+type namesServer struct {
+	*httptest.Server
+	latency  time.Duration
+	unknown  map[int64]bool
+	bulk     atomic.Int32
+	single   atomic.Int32
+	largest  atomic.Int32
+	rejected atomic.Int32
+	notChars map[int64]bool // IDs answered as corporations
+}
+
+func newNamesServer(tb testing.TB, latency time.Duration, unknown ...int64) *namesServer {
+	s := &namesServer{latency: latency, unknown: make(map[int64]bool), notChars: make(map[int64]bool)}
+	for _, id := range unknown {
+		s.unknown[id] = true
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(s.latency)
+
+		if r.Method == http.MethodPost && r.URL.Path == "/universe/names/" {
+			s.bulk.Add(1)
+			var ids []int64
+			if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if int32(len(ids)) > s.largest.Load() {
+				s.largest.Store(int32(len(ids)))
+			}
+
+			entries := make([]universeName, 0, len(ids))
+			for _, id := range ids {
+				if s.unknown[id] {
+					s.rejected.Add(1)
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error": "Ensure all IDs are valid before resolving."}`))
+					return
+				}
+				category := "character"
+				if s.notChars[id] {
+					category = "corporation"
+				}
+				entries = append(entries, universeName{Category: category, ID: id, Name: fmt.Sprintf("Pilot %d", id)})
+			}
+			_ = json.NewEncoder(w).Encode(entries)
+			return
+		}
+
+		var id int64
+		if _, err := fmt.Sscanf(r.URL.Path, "/characters/%d/", &id); err == nil {
+			s.single.Add(1)
+			if s.unknown[id] || s.notChars[id] {
+				s.rejected.Add(1)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"name": "Pilot %d"}`, id)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// client returns a client using the stand-in server.
+func (s *namesServer) client(opts ...Option) *Client {
+	return NewClient(append([]Option{WithBaseURL(s.URL), WithHTTPClient(s.Client())}, opts...)...)
+}
+
+func characterIDs(n int) []int64 {
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	return ids
+}
+
+func TestBatchGetCharacterNamesBulk(t *testing.T) {
+	server := newNamesServer(t, 0, 13, 1100)
+	server.notChars[14] = true
+
+	client := server.client()
+	client.cache[7] = &CharacterInfo{Name: "Cached Pilot"}
+
+	ids := append(characterIDs(1200), 1, 2) // duplicates are resolved once
+	names := client.BatchGetCharacterNames(context.Background(), ids)
+
+	if len(names) != 1200 {
+		t.Fatalf("expected 1200 names, got %d", len(names))
+	}
+	for id, name := range names {
+		want := fmt.Sprintf("Pilot %d", id)
+		switch id {
+		case 7:
+			want = "Cached Pilot"
+		case 13, 14, 1100:
+			want = fmt.Sprintf("Unknown (%d)", id)
+		}
+		if name != want {
+			t.Errorf("name of %d: expected %q, got %q", id, want, name)
+		}
+	}
+
+	if largest := server.largest.Load(); largest > maxNamesPerRequest {
+		t.Errorf("expected at most %d IDs per request, got %d", maxNamesPerRequest, largest)
+	}
+	if single := server.single.Load(); single > 3*minNameSplitSize {
+		t.Errorf("expected per-ID requests only around the 3 rejected IDs, got %d", single)
+	}
+	if bulk := server.bulk.Load(); bulk > 40 {
+		t.Errorf("expected few bulk requests, got %d", bulk)
+	}
+
+	// Resolved names are cached
+	before := server.bulk.Load() + server.single.Load()
+	if name := client.GetCharacterNameOrFallback(context.Background(), 500); name != "Pilot 500" {
+		t.Errorf("expected 'Pilot 500', got %q", name)
+	}
+	if id, err := client.SearchCharacterByName(context.Background(), "Pilot 500"); err != nil || id != 500 {
+		t.Errorf("expected 500, got %d, %v", id, err)
+	}
+	if after := server.bulk.Load() + server.single.Load(); after != before {
+		t.Errorf("expected cached names to need no request, got %d", after-before)
+	}
+}
+
+func TestBatchGetCharacterNamesScatteredUnknown(t *testing.T) {
+	unknown := []int64{3, 97, 211, 388, 402, 555, 690, 777, 901, 999}
+	server := newNamesServer(t, 0, unknown...)
+
+	names := server.client().BatchGetCharacterNames(context.Background(), characterIDs(1000))
+	for _, id := range unknown {
+		if want := fmt.Sprintf("Unknown (%d)", id); names[id] != want {
+			t.Errorf("name of %d: expected %q, got %q", id, want, names[id])
+		}
+	}
+	if names[500] != "Pilot 500" || len(names) != 1000 {
+		t.Errorf("expected 1000 names with 'Pilot 500', got %d and %q", len(names), names[500])
+	}
+
+	// Each split costs at most one rejection, and each unknown ID at most two:
+	// its chunk looked up one at a time, and its own per-ID request
+	if rejected := server.rejected.Load(); rejected > maxNameSplits+2*int32(len(unknown)) {
+		t.Errorf("expected at most %d rejected requests, got %d", maxNameSplits+2*len(unknown), rejected)
+	}
+	if bulk := server.bulk.Load(); bulk > 1+2*maxNameSplits {
+		t.Errorf("expected at most %d bulk requests, got %d", 1+2*maxNameSplits, bulk)
+	}
+}
+
+func TestBatchGetCharacterNamesDiskCache(t *testing.T) {
+	server := newNamesServer(t, 0)
+	cache := NewCache("")
+
+	client := server.client(WithCache(cache))
+	client.BatchGetCharacterNames(context.Background(), characterIDs(3))
+
+	entry, ok := cache.character(2)
+	if !ok || entry.Info.Name != "Pilot 2" || !entry.NameOnly {
+		t.Fatalf("expected a name-only entry for 2, got %+v", entry)
+	}
+
+	// A new run takes the names from the disk cache
+	client = server.client(WithCache(cache))
+	before := server.bulk.Load()
+	if names := client.BatchGetCharacterNames(context.Background(), characterIDs(3)); names[3] != "Pilot 3" {
+		t.Errorf("expected 'Pilot 3', got %q", names[3])
+	}
+	if server.bulk.Load() != before {
+		t.Error("expected no request for cached names")
+	}
+
+	// Full information is still fetched for a name-only entry
+	if _, err := client.GetCharacter(context.Background(), 2); err != nil {
+		t.Fatalf("GetCharacter failed: %v", err)
+	}
+	if entry, _ := cache.character(2); entry.NameOnly {
+		t.Error("expected GetCharacter to replace the name-only entry")
+	}
+}
+
+func TestBatchGetCharacterNamesUnreachable(t *testing.T) {
+	var requests atomic.Int32
+
+	client := NewClient(
+		WithHTTPClient(&http.Client{Transport: failingTransport{requests: &requests}}),
+		WithFallbackNames(func() map[int64]string {
+			return map[int64]string{2: "Backup Pilot"}
+		}),
+	)
+
+	names := client.BatchGetCharacterNames(context.Background(), characterIDs(50))
+	if names[2] != "Backup Pilot" || names[3] != "Unknown (3)" {
+		t.Errorf("unexpected names %q, %q", names[2], names[3])
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected a single request, got %d", requests.Load())
+	}
+}
+
+// benchmarkLatency is the round trip time of the stand-in ESI server.
+const benchmarkLatency = 5 * time.Millisecond
+
+func BenchmarkBatchGetCharacterNames(b *testing.B) {
+	for _, n := range []int{10, 50, 200} {
+		ids := characterIDs(n)
+		server := newNamesServer(b, benchmarkLatency)
+
+		b.Run(fmt.Sprintf("bulk/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				server.client().BatchGetCharacterNames(context.Background(), ids)
+			}
+		})
+
+		b.Run(fmt.Sprintf("per-id/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				server.client().getNamesPerID(context.Background(), ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package esi
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRetries is how many times a request failing with a server error or
+	// a rate limit is sent again.
+	maxRetries = 3
+
+	// retryBaseDelay is the delay before the first retry; it doubles with
+	// each further retry.
+	retryBaseDelay = 500 * time.Millisecond
+
+	// errorLimitReserve is the part of the ESI error budget left alone: once
+	// no more errors than this remain, requests wait for the budget to reset.
+	errorLimitReserve = 10
+
+	// statusErrorLimited is the status ESI answers with once the error budget
+	// is exhausted.
+	statusErrorLimited = 420
+)
+
+// errorBudget tracks what remains of the ESI error budget.
+//
+// ESI allows a number of error responses per time window and reports what
+// remains of it in the X-ESI-Error-Limit-Remain and X-ESI-Error-Limit-Reset
+// headers. Clients that exhaust it are blocked, so once the budget runs low
+// requests wait for the window to reset before being sent.
+type errorBudget struct {
+	mu     sync.Mutex
+	remain int       // errors left in the window, or -1 if unknown
+	reset  time.Time // end of the window
+}
+
+func newErrorBudget() *errorBudget {
+	return &errorBudget{remain: -1}
+}
+
+// sharedErrorBudget is the error budget of every Client of the process: ESI
+// counts the errors of all requests coming from the same place together.
+var sharedErrorBudget = newErrorBudget()
+
+// retryTransport sends ESI requests, staying within the ESI error budget and
+// retrying requests that fail with a server error or a rate limit.
+type retryTransport struct {
+	base   http.RoundTripper
+	budget *errorBudget
+
+	// sleep waits for d or until ctx is done; replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryTransport wraps base, or http.DefaultTransport if it is nil,
+// keeping track of the error budget in budget.
+func newRetryTransport(base http.RoundTripper, budget *errorBudget) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, budget: budget, sleep: sleepContext}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForErrorBudget(ctx); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			// The body was consumed by the previous attempt
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		t.updateErrorBudget(resp)
+
+		delay, retry := t.retryDelay(resp, attempt)
+		if !retry || attempt == maxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		// Give up rather than wait past the request's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, nil
+		}
+
+		_ = resp.Body.Close()
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay reports whether resp is worth retrying, and after how long.
+func (t *retryTransport) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		http.StatusTooManyRequests, statusErrorLimited:
+	default:
+		return 0, false
+	}
+
+	if delay, ok := retryAfter(resp); ok {
+		return delay, true
+	}
+	if resp.StatusCode == statusErrorLimited {
+		t.budget.mu.Lock()
+		defer t.budget.mu.Unlock()
+		return max(time.Until(t.budget.reset), retryBaseDelay), true
+	}
+
+	// Exponential backoff with jitter, so that concurrent requests do not
+	// retry all at once
+	backoff := retryBaseDelay << attempt
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// waitForErrorBudget waits for the error window to reset if few errors are
+// left in it.
+func (t *retryTransport) waitForErrorBudget(ctx context.Context) error {
+	t.budget.mu.Lock()
+	wait := time.Duration(0)
+	if t.budget.remain >= 0 && t.budget.remain <= errorLimitReserve {
+		wait = time.Until(t.budget.reset)
+	}
+	t.budget.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	return t.sleep(ctx, wait)
+}
+
+// updateErrorBudget records the error budget reported with resp.
+func (t *retryTransport) updateErrorBudget(resp *http.Response) {
+	remain, err := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Remain"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Reset"))
+	if err != nil {
+		return
+	}
+
+	t.budget.mu.Lock()
+	defer t.budget.mu.Unlock()
+	t.budget.remain = remain
+	t.budget.reset = time.Now().Add(time.Duration(reset) * time.Second)
+}
+
+// retryAfter returns the delay asked for by the Retry-After header of resp,
+// given in seconds or as a date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,259 @@
+package esi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTransport starts a stand-in for ESI serving handler, and returns a
+// retry transport to it recording its waits instead of sleeping.
+func newTestTransport(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *retryTransport, *[]time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	var waits []time.Duration
+	transport := newRetryTransport(server.Client().Transport, newErrorBudget())
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return server, transport, &waits
+}
+
+func TestRetryTransportServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server, transport, waits := newTestTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "[1,2]" {
+			t.Errorf("attempt %d: expected body [1,2], got %q", requests.Load()+1, body)
+		}
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte("[1,2]")))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || requests.Load() != 3 {
+		t.Errorf("expected success on the 3rd attempt, got %d after %d", resp.StatusCode, requests.Load())
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("expected 2 waits, got %v", *waits)
+	}
+	// Backoff doubles, with jitter of up to half of it
+	for i, wait := range *waits {
+		backoff := retryBaseDelay << i
+		if wait < backoff/2 || wait > backoff {
+			t.Errorf("wait %d: expected between %v and %v, got %v", i, backoff/2, backoff, wait)
+		}
+	}
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	var requests atomic.Int32
+	server, transport, _ := newTestTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway || requests.Load() != maxRetries+1 {
+		t.Errorf("expected the last 502 after %d attempts, got %d after %d",
+			maxRetries+1, resp.StatusCode, requests.Load())
+	}
+}
+
+func TestRetryTransportNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	server, transport, _ := newTestTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if requests.Load() != 1 {
+		t.Errorf("expected a 404 not to be retried, got %d attempts", requests.Load())
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server, transport, waits := newTestTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Errorf("expected a single wait of 7s, got %v", *waits)
+	}
+}
+
+func TestRetryTransportErrorLimit(t *testing.T) {
+	var requests atomic.Int32
+	server, transport, waits := newTestTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-ESI-Error-Limit-Reset", "30")
+		if requests.Add(1) == 1 {
+			w.Header().Set("X-ESI-Error-Limit-Remain", "0")
+			w.WriteHeader(statusErrorLimited)
+			return
+		}
+		w.Header().Set("X-ESI-Error-Limit-Remain", "100")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected success after the error limit reset, got %d", resp.StatusCode)
+	}
+	if len(*waits) == 0 || (*waits)[0] < 29*time.Second || (*waits)[0] > 30*time.Second {
+		t.Errorf("expected to wait for the error limit to reset, got %v", *waits)
+	}
+}
+
+func TestRetryTransportErrorBudget(t *testing.T) {
+	remain := "50"
+	server, transport, waits := newTestTransport(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-ESI-Error-Limit-Remain", remain)
+		w.Header().Set("X-ESI-Error-Limit-Reset", "30")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	get := func() {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip failed: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	get()
+	get()
+	if len(*waits) != 0 {
+		t.Errorf("expected no wait with errors to spare, got %v", *waits)
+	}
+
+	remain = "5"
+	get()
+	get()
+	if len(*waits) != 1 || (*waits)[0] < 29*time.Second || (*waits)[0] > 30*time.Second {
+		t.Errorf("expected to wait for the error budget to reset, got %v", *waits)
+	}
+}
+
+func TestRetryTransportCancelled(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	transport := newRetryTransport(server.Client().Transport, newErrorBudget())
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected no retry once cancelled, got %d attempts", requests.Load())
+	}
+}
+
+func TestClientCancelled(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"players": 1}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GetStatus(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := client.ResolveCharacter(ctx, "Nobody"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from ResolveCharacter, got %v", err)
+	}
+	if !client.Online() {
+		t.Error("expected a cancelled request not to turn the client offline")
+	}
+}
+
+func TestClientsShareErrorBudget(t *testing.T) {
+	budget := sharedErrorBudget
+	sharedErrorBudget = newErrorBudget()
+	t.Cleanup(func() { sharedErrorBudget = budget })
+
+	var requests atomic.Int32
+	server, first := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-ESI-Error-Limit-Remain", "5")
+		w.Header().Set("X-ESI-Error-Limit-Reset", "30")
+		_, _ = w.Write([]byte(`{"players": 1}`))
+	})
+	if _, err := first.GetStatus(context.Background()); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+
+	// Another client waits for the budget the first one saw to reset
+	second := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := second.GetStatus(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the second client to wait for the error budget, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", requests.Load())
+	}
+}
@@ -0,0 +1,195 @@
+package eve
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// coOccurrenceWindow is the maximum difference in seconds between a character
+// file and a user file modification time for both to count as written by the
+// same client session. The client flushes both files together on logout.
+const coOccurrenceWindow = 30
+
+// AccountSource describes how a character was paired with an account.
+type AccountSource string
+
+const (
+	// AccountLinked means the pairing was supplied by the user.
+	AccountLinked AccountSource = "linked"
+	// AccountInferred means the pairing was inferred from modification times.
+	AccountInferred AccountSource = "inferred"
+)
+
+// AccountLink pairs a character with the account user file that owns it.
+type AccountLink struct {
+	UserID   int64
+	Source   AccountSource
+	Sessions int // co-occurring sessions backing an inferred link
+}
+
+// AccountStore persists character to account pairings across runs.
+// It holds user-supplied links and the modification-time co-occurrences
+// observed so far, which are used to infer pairings for unlinked characters.
+type AccountStore struct {
+	Links        map[int64]int64               `json:"links"`
+	Observations map[int64]*AccountObservation `json:"observations"`
+
+	path string
+}
+
+// AccountObservation records which user files were written alongside a
+// character file, one count per observed client session.
+type AccountObservation struct {
+	LastModTime int64         `json:"last_mod_time"`
+	Counts      map[int64]int `json:"counts"`
+}
+
+// DefaultAccountStorePath returns the location of the account store in the
+// user's configuration directory.
+func DefaultAccountStorePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "esm", "accounts.json"), nil
+}
+
+// NewAccountStore creates an empty account store that is saved to path.
+func NewAccountStore(path string) *AccountStore {
+	return &AccountStore{
+		Links:        make(map[int64]int64),
+		Observations: make(map[int64]*AccountObservation),
+		path:         path,
+	}
+}
+
+// LoadAccountStore reads the account store at path.
+// A missing file yields an empty store that will be created on Save.
+func LoadAccountStore(path string) (*AccountStore, error) {
+	store := NewAccountStore(path)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse account store %s: %w", path, err)
+	}
+
+	// A file written by hand may omit either section
+	if store.Links == nil {
+		store.Links = make(map[int64]int64)
+	}
+	if store.Observations == nil {
+		store.Observations = make(map[int64]*AccountObservation)
+	}
+
+	return store, nil
+}
+
+// Save writes the account store back to disk.
+func (s *AccountStore) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create account store directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode account store: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write account store: %w", err)
+	}
+	return nil
+}
+
+// Link records a user-supplied pairing, which always wins over inference.
+func (s *AccountStore) Link(charID, userID int64) {
+	s.Links[charID] = userID
+}
+
+// Unlink removes a user-supplied pairing. It reports whether one existed.
+func (s *AccountStore) Unlink(charID int64) bool {
+	if _, ok := s.Links[charID]; !ok {
+		return false
+	}
+	delete(s.Links, charID)
+	return true
+}
+
+// Observe records the user files written in the same session as each character
+// file. Only the most recent file of a character with settings in several
+// folders is looked at, and a character is only counted again once it has been
+// written since, so repeated runs do not inflate the counts. It reports whether
+// anything changed.
+func (s *AccountStore) Observe(chars []CharacterSettings, users []UserSettings) bool {
+	changed := false
+
+	for _, files := range GroupCharacters(chars) {
+		c := files[0]
+		obs, ok := s.Observations[c.CharacterID]
+		if !ok {
+			obs = &AccountObservation{Counts: make(map[int64]int)}
+			s.Observations[c.CharacterID] = obs
+		}
+		if obs.Counts == nil {
+			obs.Counts = make(map[int64]int)
+		}
+
+		if c.ModTime <= obs.LastModTime {
+			continue
+		}
+		obs.LastModTime = c.ModTime
+		changed = true
+
+		charDir := c.GetSettingsDir()
+		for _, u := range users {
+			if u.GetSettingsDir() != charDir {
+				continue
+			}
+			if diff := u.ModTime - c.ModTime; diff >= -coOccurrenceWindow && diff <= coOccurrenceWindow {
+				obs.Counts[u.UserID]++
+			}
+		}
+	}
+
+	return changed
+}
+
+// Resolve returns the account pairing for a character.
+// User-supplied links take precedence. Otherwise the user file that co-occurred
+// in the most sessions is returned, provided it strictly beats every other one.
+func (s *AccountStore) Resolve(charID int64) (AccountLink, bool) {
+	if userID, ok := s.Links[charID]; ok {
+		return AccountLink{UserID: userID, Source: AccountLinked}, true
+	}
+
+	obs, ok := s.Observations[charID]
+	if !ok {
+		return AccountLink{}, false
+	}
+
+	var best AccountLink
+	runnerUp := 0
+	for userID, count := range obs.Counts {
+		switch {
+		case count > best.Sessions:
+			runnerUp = best.Sessions
+			best = AccountLink{UserID: userID, Source: AccountInferred, Sessions: count}
+		case count > runnerUp:
+			runnerUp = count
+		}
+	}
+
+	if best.Sessions == 0 || best.Sessions == runnerUp {
+		return AccountLink{}, false
+	}
+	return best, true
+}
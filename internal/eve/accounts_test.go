@@ -0,0 +1,122 @@
+package eve
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAccountStoreObserveAndResolve(t *testing.T) {
+	dir := filepath.Join("settings", "settings_Default")
+	store := NewAccountStore(filepath.Join(t.TempDir(), "accounts.json"))
+
+	chars := []CharacterSettings{
+		{CharacterID: 1, FilePath: filepath.Join(dir, "core_char_1.dat"), ModTime: 1000},
+		{CharacterID: 2, FilePath: filepath.Join(dir, "core_char_2.dat"), ModTime: 5000},
+	}
+	users := []UserSettings{
+		{UserID: 10, FilePath: filepath.Join(dir, "core_user_10.dat"), ModTime: 1005},
+		{UserID: 20, FilePath: filepath.Join(dir, "core_user_20.dat"), ModTime: 9000},
+	}
+
+	if !store.Observe(chars, users) {
+		t.Fatal("expected first observation to change the store")
+	}
+	if store.Observe(chars, users) {
+		t.Error("observing unchanged files should not change the store")
+	}
+
+	link, ok := store.Resolve(1)
+	if !ok || link.UserID != 10 || link.Source != AccountInferred {
+		t.Errorf("Resolve(1) = %+v, %v; want inferred user 10", link, ok)
+	}
+
+	if _, ok := store.Resolve(2); ok {
+		t.Error("character 2 has no co-occurring user file and should not resolve")
+	}
+
+	store.Link(2, 20)
+	link, ok = store.Resolve(2)
+	if !ok || link.UserID != 20 || link.Source != AccountLinked {
+		t.Errorf("Resolve(2) = %+v, %v; want linked user 20", link, ok)
+	}
+}
+
+func TestAccountStoreObserveSeveralFolders(t *testing.T) {
+	tq := filepath.Join("tq", "settings_Default")
+	sisi := filepath.Join("sisi", "settings_Default")
+	store := NewAccountStore(filepath.Join(t.TempDir(), "accounts.json"))
+
+	chars := []CharacterSettings{
+		{CharacterID: 1, FilePath: filepath.Join(tq, "core_char_1.dat"), ModTime: 1000},
+		{CharacterID: 1, FilePath: filepath.Join(sisi, "core_char_1.dat"), ModTime: 5000},
+	}
+	users := []UserSettings{
+		{UserID: 10, FilePath: filepath.Join(tq, "core_user_10.dat"), ModTime: 1000},
+		{UserID: 20, FilePath: filepath.Join(sisi, "core_user_20.dat"), ModTime: 5000},
+	}
+
+	if !store.Observe(chars, users) {
+		t.Fatal("expected first observation to change the store")
+	}
+	want := map[int64]int{20: 1}
+	for i := 0; i < 2; i++ {
+		if store.Observe(chars, users) {
+			t.Error("observing unchanged files should not change the store")
+		}
+		if counts := store.Observations[1].Counts; len(counts) != len(want) || counts[20] != want[20] {
+			t.Errorf("counts = %v, want %v", counts, want)
+		}
+	}
+}
+
+func TestAccountStoreAmbiguousSessions(t *testing.T) {
+	dir := filepath.Join("settings", "settings_Default")
+	store := NewAccountStore(filepath.Join(t.TempDir(), "accounts.json"))
+
+	char := CharacterSettings{CharacterID: 1, FilePath: filepath.Join(dir, "core_char_1.dat"), ModTime: 1000}
+	users := []UserSettings{
+		{UserID: 10, FilePath: filepath.Join(dir, "core_user_10.dat"), ModTime: 1000},
+		{UserID: 20, FilePath: filepath.Join(dir, "core_user_20.dat"), ModTime: 1010},
+	}
+
+	// Two accounts logged out together: no winner yet
+	store.Observe([]CharacterSettings{char}, users)
+	if _, ok := store.Resolve(1); ok {
+		t.Fatal("tied co-occurrence should not resolve")
+	}
+
+	// A later session where only user 10 was written breaks the tie
+	char.ModTime = 2000
+	users[0].ModTime = 2003
+	store.Observe([]CharacterSettings{char}, users)
+
+	link, ok := store.Resolve(1)
+	if !ok || link.UserID != 10 || link.Sessions != 2 {
+		t.Errorf("Resolve(1) = %+v, %v; want user 10 with 2 sessions", link, ok)
+	}
+}
+
+func TestAccountStoreSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "esm", "accounts.json")
+
+	store, err := LoadAccountStore(path)
+	if err != nil {
+		t.Fatalf("LoadAccountStore on missing file failed: %v", err)
+	}
+	store.Link(123, 456)
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadAccountStore(path)
+	if err != nil {
+		t.Fatalf("LoadAccountStore failed: %v", err)
+	}
+	if loaded.Links[123] != 456 {
+		t.Errorf("expected link 123 -> 456, got %v", loaded.Links)
+	}
+
+	if !loaded.Unlink(123) || loaded.Unlink(123) {
+		t.Error("Unlink should report true once, then false")
+	}
+}
@@ -26,11 +26,34 @@ func CopySettings(from, to *CharacterSettings, backupDir string) error {
 	return copyFile(from.FilePath, to.FilePath)
 }
 
+// CopyUserSettings copies account settings from one user file to another.
+// It creates a backup of the target file before overwriting.
+func CopyUserSettings(from, to *UserSettings, backupDir string) error {
+	// Create backup of target if it exists
+	if _, err := os.Stat(to.FilePath); err == nil {
+		if backupDir != "" {
+			backupPath := filepath.Join(backupDir, fmt.Sprintf("core_user_%d_%s.dat.bak",
+				to.UserID, time.Now().Format("20060102_150405")))
+			if err := copyFile(to.FilePath, backupPath); err != nil {
+				return fmt.Errorf("failed to create backup: %w", err)
+			}
+		}
+	}
+
+	// Copy source to target
+	return copyFile(from.FilePath, to.FilePath)
+}
+
 // GetSettingsDir returns the directory containing the settings file.
 func (cs *CharacterSettings) GetSettingsDir() string {
 	return filepath.Dir(cs.FilePath)
 }
 
+// GetSettingsDir returns the directory containing the settings file.
+func (us *UserSettings) GetSettingsDir() string {
+	return filepath.Dir(us.FilePath)
+}
+
 // copyFile copies a file from src to dst.
 func copyFile(src, dst string) (err error) {
 	// Ensure destination directory exists
@@ -65,6 +88,17 @@ func copyFile(src, dst string) (err error) {
 // CreateCharacterSettingsPath generates a path for a new character settings file.
 // Uses the same directory as the reference character.
 func CreateCharacterSettingsPath(referenceChar *CharacterSettings, newCharID int64) string {
-	dir := referenceChar.GetSettingsDir()
-	return filepath.Join(dir, fmt.Sprintf("core_char_%d.dat", newCharID))
+	return CreateCharacterSettingsPathIn(referenceChar.GetSettingsDir(), newCharID)
+}
+
+// CreateCharacterSettingsPathIn generates a path for a new character settings
+// file in the given settings directory.
+func CreateCharacterSettingsPathIn(settingsDir string, newCharID int64) string {
+	return filepath.Join(settingsDir, fmt.Sprintf("core_char_%d.dat", newCharID))
+}
+
+// CreateUserSettingsPath generates a path for a new account user settings file.
+// The file is placed in the given settings directory.
+func CreateUserSettingsPath(settingsDir string, newUserID int64) string {
+	return filepath.Join(settingsDir, fmt.Sprintf("core_user_%d.dat", newUserID))
 }
@@ -1,12 +1,20 @@
 package eve
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 )
 
+// Names of character and account user settings files; the submatch is the ID.
+var (
+	charFilePattern = regexp.MustCompile(`^core_char_(\d+)\.dat$`)
+	userFilePattern = regexp.MustCompile(`^core_user_(\d+)\.dat$`)
+)
+
 // CharacterSettings represents a character's settings file.
 type CharacterSettings struct {
 	CharacterID int64
@@ -14,54 +22,68 @@ type CharacterSettings struct {
 	ModTime     int64 // Unix timestamp
 }
 
-// DetectSettingsDirectories finds all Eve settings directories.
+// UserSettings represents an account-wide settings file (core_user_*.dat).
+// It holds the account's window layouts, audio and graphics preferences.
+type UserSettings struct {
+	UserID   int64
+	FilePath string
+	ModTime  int64 // Unix timestamp
+}
+
+// DetectSettingsDirectories finds all Eve settings directories of all
+// installations. Like DetectInstallations, it may return a Warnings error
+// together with the directories.
 func DetectSettingsDirectories() ([]string, error) {
-	var settingsDirs []string
-	basePaths := GetPossibleSettingsPaths()
+	installs, err := DetectInstallations()
+	if err != nil && !IsWarnings(err) {
+		return nil, err
+	}
+	return SettingsDirectories(installs), err
+}
 
-	for _, basePath := range basePaths {
-		if _, err := os.Stat(basePath); os.IsNotExist(err) {
-			continue
-		}
+// FindCharacterSettings finds all core_char_*.dat files in the given directories.
+// Directories and files that cannot be read are reported in a Warnings error
+// returned with the characters found.
+func FindCharacterSettings(settingsDirs []string) ([]CharacterSettings, error) {
+	var characters []CharacterSettings
 
-		// Look for directories containing settings_* subdirectories
-		entries, err := os.ReadDir(basePath)
-		if err != nil {
-			continue
-		}
+	err := findSettingsFiles(settingsDirs, charFilePattern, func(id int64, path string, modTime int64) {
+		characters = append(characters, CharacterSettings{
+			CharacterID: id,
+			FilePath:    path,
+			ModTime:     modTime,
+		})
+	})
 
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				continue
-			}
+	return characters, err
+}
 
-			// Check for settings_* subdirectories (e.g., settings_Default)
-			profilePath := filepath.Join(basePath, entry.Name())
-			profileEntries, err := os.ReadDir(profilePath)
-			if err != nil {
-				continue
-			}
+// FindUserSettings finds all core_user_*.dat files in the given directories.
+// Problems are reported like in FindCharacterSettings.
+func FindUserSettings(settingsDirs []string) ([]UserSettings, error) {
+	var users []UserSettings
 
-			for _, profileEntry := range profileEntries {
-				if profileEntry.IsDir() && regexp.MustCompile(`^settings_`).MatchString(profileEntry.Name()) {
-					settingsDir := filepath.Join(profilePath, profileEntry.Name())
-					settingsDirs = append(settingsDirs, settingsDir)
-				}
-			}
-		}
-	}
+	err := findSettingsFiles(settingsDirs, userFilePattern, func(id int64, path string, modTime int64) {
+		users = append(users, UserSettings{
+			UserID:   id,
+			FilePath: path,
+			ModTime:  modTime,
+		})
+	})
 
-	return settingsDirs, nil
+	return users, err
 }
 
-// FindCharacterSettings finds all core_char_*.dat files in the given directories.
-func FindCharacterSettings(settingsDirs []string) ([]CharacterSettings, error) {
-	var characters []CharacterSettings
-	charFilePattern := regexp.MustCompile(`^core_char_(\d+)\.dat$`)
+// findSettingsFiles calls found for every file in settingsDirs whose name matches
+// pattern. The first submatch of pattern must capture the numeric ID. Paths
+// that cannot be read are returned as Warnings.
+func findSettingsFiles(settingsDirs []string, pattern *regexp.Regexp, found func(id int64, path string, modTime int64)) error {
+	var warnings Warnings
 
 	for _, dir := range settingsDirs {
 		entries, err := os.ReadDir(dir)
 		if err != nil {
+			warnings.add(dir, err)
 			continue
 		}
 
@@ -70,49 +92,129 @@ func FindCharacterSettings(settingsDirs []string) ([]CharacterSettings, error) {
 				continue
 			}
 
-			matches := charFilePattern.FindStringSubmatch(entry.Name())
+			matches := pattern.FindStringSubmatch(entry.Name())
 			if matches == nil {
 				continue
 			}
 
-			charID, err := strconv.ParseInt(matches[1], 10, 64)
+			path := filepath.Join(dir, entry.Name())
+			id, err := strconv.ParseInt(matches[1], 10, 64)
 			if err != nil {
+				warnings.add(path, err)
 				continue
 			}
 
 			info, err := entry.Info()
 			if err != nil {
+				warnings.add(path, err)
 				continue
 			}
 
-			characters = append(characters, CharacterSettings{
-				CharacterID: charID,
-				FilePath:    filepath.Join(dir, entry.Name()),
-				ModTime:     info.ModTime().Unix(),
-			})
+			found(id, path, info.ModTime().Unix())
+		}
+	}
+
+	return warnings.err()
+}
+
+// DuplicateCharacterError is returned when a character has settings files in
+// several folders and one of them has to be chosen.
+type DuplicateCharacterError struct {
+	CharacterID int64
+	Files       []CharacterSettings // most recently modified first
+}
+
+func (e *DuplicateCharacterError) Error() string {
+	return fmt.Sprintf("character %d has settings in %d folders", e.CharacterID, len(e.Files))
+}
+
+// CharacterFiles returns the settings files of charID among chars, most
+// recently modified first.
+func CharacterFiles(chars []CharacterSettings, charID int64) []CharacterSettings {
+	var files []CharacterSettings
+	for _, c := range chars {
+		if c.CharacterID == charID {
+			files = append(files, c)
 		}
 	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime > files[j].ModTime
+	})
+	return files
+}
 
-	return characters, nil
+// UserFiles returns the account user files of userID among users, most
+// recently modified first.
+func UserFiles(users []UserSettings, userID int64) []UserSettings {
+	var files []UserSettings
+	for _, u := range users {
+		if u.UserID == userID {
+			files = append(files, u)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime > files[j].ModTime
+	})
+	return files
 }
 
-// FindCharacterByID finds a character settings file by character ID.
+// GroupCharacters groups chars by character ID, in the order the characters
+// first appear. The files of each character are sorted like in
+// CharacterFiles.
+func GroupCharacters(chars []CharacterSettings) [][]CharacterSettings {
+	var groups [][]CharacterSettings
+	seen := make(map[int64]bool)
+	for _, c := range chars {
+		if !seen[c.CharacterID] {
+			seen[c.CharacterID] = true
+			groups = append(groups, CharacterFiles(chars, c.CharacterID))
+		}
+	}
+	return groups
+}
+
+// FindCharacterByID finds a character settings file by character ID. If it is
+// not found, the Warnings met while searching are returned; if it is found in
+// several folders, a DuplicateCharacterError.
 func FindCharacterByID(charID int64) (*CharacterSettings, error) {
-	dirs, err := DetectSettingsDirectories()
-	if err != nil {
-		return nil, err
+	dirs, dirErr := DetectSettingsDirectories()
+	if dirErr != nil && !IsWarnings(dirErr) {
+		return nil, dirErr
 	}
 
 	characters, err := FindCharacterSettings(dirs)
-	if err != nil {
+	if err != nil && !IsWarnings(err) {
+		return nil, err
+	}
+
+	switch files := CharacterFiles(characters, charID); len(files) {
+	case 0:
+		return nil, joinWarnings(dirErr, err)
+	case 1:
+		return &files[0], nil
+	default:
+		return nil, &DuplicateCharacterError{CharacterID: charID, Files: files}
+	}
+}
+
+// FindUserByID finds an account user settings file by user ID. Problems are
+// reported like in FindCharacterByID.
+func FindUserByID(userID int64) (*UserSettings, error) {
+	dirs, dirErr := DetectSettingsDirectories()
+	if dirErr != nil && !IsWarnings(dirErr) {
+		return nil, dirErr
+	}
+
+	users, err := FindUserSettings(dirs)
+	if err != nil && !IsWarnings(err) {
 		return nil, err
 	}
 
-	for _, char := range characters {
-		if char.CharacterID == charID {
-			return &char, nil
+	for _, user := range users {
+		if user.UserID == userID {
+			return &user, nil
 		}
 	}
 
-	return nil, nil
+	return nil, joinWarnings(dirErr, err)
 }
@@ -58,6 +58,40 @@ func TestFindCharacterSettings(t *testing.T) {
 	}
 }
 
+func TestFindUserSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	settingsDir := filepath.Join(tempDir, "settings_Default")
+	if err := os.MkdirAll(settingsDir, 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+
+	for _, name := range []string{"core_char_12345678.dat", "core_user_99999.dat", "core_user_88888.dat", "core_user_abc.dat"} {
+		if err := os.WriteFile(filepath.Join(settingsDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	users, err := FindUserSettings([]string{settingsDir})
+	if err != nil {
+		t.Fatalf("FindUserSettings failed: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+
+	foundIDs := make(map[int64]bool)
+	for _, u := range users {
+		foundIDs[u.UserID] = true
+	}
+
+	for _, id := range []int64{99999, 88888} {
+		if !foundIDs[id] {
+			t.Errorf("expected to find user %d", id)
+		}
+	}
+}
+
 func TestDetectSettingsDirectories(t *testing.T) {
 	// This test verifies the function doesn't panic on various systems
 	// Actual directories may or may not exist
@@ -85,6 +119,50 @@ func TestCharacterSettingsGetSettingsDir(t *testing.T) {
 	}
 }
 
+func TestCopyUserSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	src := &UserSettings{UserID: 1, FilePath: filepath.Join(tempDir, "core_user_1.dat")}
+	dst := &UserSettings{UserID: 2, FilePath: filepath.Join(tempDir, "core_user_2.dat")}
+
+	if err := os.WriteFile(src.FilePath, []byte("source"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(dst.FilePath, []byte("target"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	backupDir := filepath.Join(tempDir, "backups")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatalf("failed to create backup directory: %v", err)
+	}
+
+	if err := CopyUserSettings(src, dst, backupDir); err != nil {
+		t.Fatalf("CopyUserSettings failed: %v", err)
+	}
+
+	got, err := os.ReadFile(dst.FilePath)
+	if err != nil {
+		t.Fatalf("failed to read target: %v", err)
+	}
+	if string(got) != "source" {
+		t.Errorf("target content = %q, want %q", got, "source")
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(backupDir, "core_user_2_*.dat.bak"))
+	if len(backups) != 1 {
+		t.Errorf("expected 1 backup file, got %d", len(backups))
+	}
+}
+
+func TestCreateUserSettingsPath(t *testing.T) {
+	newPath := CreateUserSettingsPath("/path/to/settings_Default", 67890)
+	expected := "/path/to/settings_Default/core_user_67890.dat"
+
+	if newPath != expected {
+		t.Errorf("CreateUserSettingsPath() = %s, want %s", newPath, expected)
+	}
+}
+
 func TestCreateCharacterSettingsPath(t *testing.T) {
 	ref := &CharacterSettings{
 		CharacterID: 12345,
@@ -98,3 +176,40 @@ func TestCreateCharacterSettingsPath(t *testing.T) {
 		t.Errorf("CreateCharacterSettingsPath() = %s, want %s", newPath, expected)
 	}
 }
+
+func TestGroupCharacters(t *testing.T) {
+	chars := []CharacterSettings{
+		{CharacterID: 1, FilePath: "/a/settings_Default/core_char_1.dat", ModTime: 100},
+		{CharacterID: 2, FilePath: "/a/settings_Default/core_char_2.dat", ModTime: 300},
+		{CharacterID: 1, FilePath: "/b/settings_Default/core_char_1.dat", ModTime: 200},
+	}
+
+	files := CharacterFiles(chars, 1)
+	if len(files) != 2 || files[0].FilePath != "/b/settings_Default/core_char_1.dat" {
+		t.Errorf("CharacterFiles() = %+v, want newest first", files)
+	}
+	if files := CharacterFiles(chars, 3); len(files) != 0 {
+		t.Errorf("CharacterFiles() = %+v, want none", files)
+	}
+
+	groups := GroupCharacters(chars)
+	if len(groups) != 2 || len(groups[0]) != 2 || groups[0][0].ModTime != 200 || groups[1][0].CharacterID != 2 {
+		t.Errorf("GroupCharacters() = %+v", groups)
+	}
+}
+
+func TestUserFiles(t *testing.T) {
+	users := []UserSettings{
+		{UserID: 10, FilePath: "/a/settings_Default/core_user_10.dat", ModTime: 100},
+		{UserID: 20, FilePath: "/a/settings_Default/core_user_20.dat", ModTime: 300},
+		{UserID: 10, FilePath: "/b/settings_Default/core_user_10.dat", ModTime: 200},
+	}
+
+	files := UserFiles(users, 10)
+	if len(files) != 2 || files[0].FilePath != "/b/settings_Default/core_user_10.dat" {
+		t.Errorf("UserFiles() = %+v, want newest first", files)
+	}
+	if files := UserFiles(users, 30); len(files) != 0 {
+		t.Errorf("UserFiles() = %+v, want none", files)
+	}
+}
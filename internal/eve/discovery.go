@@ -0,0 +1,328 @@
+package eve
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Launchers through which an Eve installation can be found.
+const (
+	LauncherNative    = "native"
+	LauncherSteam     = "steam"
+	LauncherLutris    = "lutris"
+	LauncherBottles   = "bottles"
+	LauncherHeroic    = "heroic"
+	LauncherCrossOver = "crossover"
+	LauncherWine      = "wine"
+	LauncherCustom    = "custom" // configured by the user
+)
+
+// eveSteamAppID is the Steam application ID of Eve Online.
+const eveSteamAppID = "8500"
+
+// steamRoots returns the Eve settings paths in the Proton prefix of every Steam
+// library listed in libraryfolders.vdf, starting with the default library.
+func steamRoots(home string) []SearchRoot {
+	steamDirs := []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam"),
+	}
+
+	var libraries []string
+	for i, dir := range steamDirs {
+		// The first two are the classic locations and always searched
+		if _, err := os.Stat(dir); err == nil || i < 2 {
+			libraries = append(libraries, dir)
+		}
+		libraries = append(libraries, steamLibraries(dir)...)
+	}
+
+	var roots []SearchRoot
+	for _, library := range libraries {
+		prefix := filepath.Join(library, "steamapps", "compatdata", eveSteamAppID, "pfx")
+		paths := existingPrefixPaths(prefix)
+		if len(paths) == 0 {
+			paths = []string{prefixSettingsPath(prefix, "steamuser")}
+		}
+		roots = append(roots, prefixRoots(paths, LauncherSteam)...)
+	}
+	return roots
+}
+
+// steamLibraries returns the library folders listed in a Steam installation's
+// libraryfolders.vdf.
+func steamLibraries(steamDir string) []string {
+	var data []byte
+	for _, name := range []string{
+		filepath.Join(steamDir, "steamapps", "libraryfolders.vdf"),
+		filepath.Join(steamDir, "config", "libraryfolders.vdf"),
+	} {
+		var err error
+		if data, err = os.ReadFile(name); err == nil {
+			break
+		}
+	}
+	if data == nil {
+		return nil
+	}
+
+	doc, err := parseVDF(string(data))
+	if err != nil {
+		return nil
+	}
+
+	var libraries []string
+	for key, value := range doc {
+		if !strings.EqualFold(key, "libraryfolders") {
+			continue
+		}
+		folders, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, id := range libraryIDs(folders) {
+			switch f := folders[id].(type) {
+			case map[string]any:
+				if path, ok := f["path"].(string); ok {
+					libraries = append(libraries, path)
+				}
+			case string:
+				// Older Steam versions list numbered paths directly
+				if _, err := strconv.Atoi(id); err == nil {
+					libraries = append(libraries, f)
+				}
+			}
+		}
+	}
+	return libraries
+}
+
+// libraryIDs returns the keys of the libraries listed in libraryfolders.vdf
+// in Steam's order: numbered ones first, from the default library "0", then
+// any others by name.
+func libraryIDs(folders map[string]any) []string {
+	ids := make([]string, 0, len(folders))
+	for id := range folders {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, aErr := strconv.Atoi(ids[i])
+		b, bErr := strconv.Atoi(ids[j])
+		switch {
+		case aErr == nil && bErr == nil:
+			return a < b
+		case aErr == nil || bErr == nil:
+			return aErr == nil
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// parseVDF parses Valve's KeyValues text format, as used by
+// libraryfolders.vdf, into nested maps of strings.
+func parseVDF(data string) (map[string]any, error) {
+	tokens, err := vdfTokens(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := 0
+	var parseObject func(nested bool) (map[string]any, error)
+	parseObject = func(nested bool) (map[string]any, error) {
+		obj := make(map[string]any)
+		for pos < len(tokens) {
+			key := tokens[pos]
+			pos++
+			if key == "}" {
+				if !nested {
+					return nil, fmt.Errorf("unexpected '}'")
+				}
+				return obj, nil
+			}
+			if key == "{" || pos >= len(tokens) {
+				return nil, fmt.Errorf("expected value for key '%s'", key)
+			}
+
+			value := tokens[pos]
+			pos++
+			switch value {
+			case "{":
+				child, err := parseObject(true)
+				if err != nil {
+					return nil, err
+				}
+				obj[key] = child
+			case "}":
+				return nil, fmt.Errorf("expected value for key '%s'", key)
+			default:
+				obj[key] = value
+			}
+		}
+		if nested {
+			return nil, fmt.Errorf("missing '}'")
+		}
+		return obj, nil
+	}
+
+	return parseObject(false)
+}
+
+// vdfTokens splits KeyValues text into strings and braces. Quotes are removed
+// from strings; comments and conditionals such as [$WIN32] are dropped.
+func vdfTokens(data string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(data[i:], "//"):
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == '{' || c == '}':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '[':
+			end := strings.IndexByte(data[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated conditional")
+			}
+			i += end + 1
+		case c == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+					switch data[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(data[i])
+					}
+					continue
+				}
+				b.WriteByte(data[i])
+			}
+			if i >= len(data) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, b.String())
+			i++
+		default:
+			start := i
+			for i < len(data) && !unicode.IsSpace(rune(data[i])) && !strings.ContainsRune(`{}"`, rune(data[i])) {
+				i++
+			}
+			tokens = append(tokens, data[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// lutrisRoots returns the Eve settings paths in the Wine prefixes of Lutris
+// games. The default prefix of Lutris' Eve installer is always searched.
+func lutrisRoots(home string) []SearchRoot {
+	roots := prefixRoots(winePrefixPaths(filepath.Join(home, "Games", "eve-online")), LauncherLutris)
+
+	for _, dir := range []string{
+		filepath.Join(home, ".config", "lutris", "games"),
+		filepath.Join(home, ".local", "share", "lutris", "games"),
+		filepath.Join(home, ".var", "app", "net.lutris.Lutris", "config", "lutris", "games"),
+		filepath.Join(home, ".var", "app", "net.lutris.Lutris", "data", "lutris", "games"),
+	} {
+		configs, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+		for _, name := range configs {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				continue
+			}
+			var game struct {
+				Game struct {
+					Prefix string `yaml:"prefix"`
+				} `yaml:"game"`
+			}
+			if err := yaml.Unmarshal(data, &game); err != nil || game.Game.Prefix == "" {
+				continue
+			}
+			prefix := expandHome(game.Game.Prefix, home)
+			roots = append(roots, prefixRoots(existingPrefixPaths(prefix), LauncherLutris)...)
+		}
+	}
+	return roots
+}
+
+// bottlesRoots returns the Eve settings paths found in Bottles.
+func bottlesRoots(home string) []SearchRoot {
+	var roots []SearchRoot
+	for _, dir := range []string{
+		filepath.Join(home, ".local", "share", "bottles", "bottles"),
+		filepath.Join(home, ".var", "app", "com.usebottles.bottles", "data", "bottles", "bottles"),
+	} {
+		bottles, _ := filepath.Glob(filepath.Join(dir, "*"))
+		for _, bottle := range bottles {
+			roots = append(roots, prefixRoots(existingPrefixPaths(bottle), LauncherBottles)...)
+		}
+	}
+	return roots
+}
+
+// heroicRoots returns the Eve settings paths found in the Wine prefixes of
+// Heroic Games Launcher, both the default ones and those set per game.
+func heroicRoots(home string) []SearchRoot {
+	prefixes, _ := filepath.Glob(filepath.Join(home, "Games", "Heroic", "Prefixes", "*"))
+
+	for _, dir := range []string{
+		filepath.Join(home, ".config", "heroic"),
+		filepath.Join(home, ".var", "app", "com.heroicgameslauncher.hgl", "config", "heroic"),
+	} {
+		configs, _ := filepath.Glob(filepath.Join(dir, "GamesConfig", "*.json"))
+		for _, name := range configs {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				continue
+			}
+			var games map[string]json.RawMessage
+			if err := json.Unmarshal(data, &games); err != nil {
+				continue
+			}
+			for _, raw := range games {
+				var game struct {
+					WinePrefix string `json:"winePrefix"`
+				}
+				if json.Unmarshal(raw, &game) == nil && game.WinePrefix != "" {
+					prefixes = append(prefixes, expandHome(game.WinePrefix, home))
+				}
+			}
+		}
+	}
+
+	var roots []SearchRoot
+	for _, prefix := range prefixes {
+		roots = append(roots, prefixRoots(existingPrefixPaths(prefix), LauncherHeroic)...)
+	}
+	return roots
+}
+
+// expandHome replaces a leading ~ in path with home.
+func expandHome(path, home string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
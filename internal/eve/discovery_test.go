@@ -0,0 +1,243 @@
+package eve
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseVDF(t *testing.T) {
+	data := `// written by Steam
+"libraryfolders"
+{
+	"0"
+	{
+		"path"		"/home/pilot/.local/share/Steam"
+		"label"		""
+		"apps"
+		{
+			"8500"		"1234"
+		}
+	}
+	"1"
+	{
+		"path"		"D:\\SteamLibrary"
+	}
+	"contentstatsid"	"-123" [$WIN32]
+}
+`
+	doc, err := parseVDF(data)
+	if err != nil {
+		t.Fatalf("parseVDF failed: %v", err)
+	}
+
+	folders, ok := doc["libraryfolders"].(map[string]any)
+	if !ok || len(folders) != 3 {
+		t.Fatalf("unexpected document: %v", doc)
+	}
+	first := folders["0"].(map[string]any)
+	if first["path"] != "/home/pilot/.local/share/Steam" || first["label"] != "" {
+		t.Errorf("unexpected first folder: %v", first)
+	}
+	if apps := first["apps"].(map[string]any); apps["8500"] != "1234" {
+		t.Errorf("unexpected apps: %v", apps)
+	}
+	if got := folders["1"].(map[string]any)["path"]; got != `D:\SteamLibrary` {
+		t.Errorf("escaped path = %v", got)
+	}
+
+	for _, bad := range []string{`"a" {`, `"a" }`, `}`, `"a`, `"a"`} {
+		if _, err := parseVDF(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSteamLibrariesOrder(t *testing.T) {
+	steam := t.TempDir()
+	vdf := filepath.Join(steam, "steamapps", "libraryfolders.vdf")
+	if err := os.MkdirAll(filepath.Dir(vdf), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(vdf, []byte(`"libraryfolders"
+{
+	"10" { "path" "/lib10" }
+	"2" { "path" "/lib2" }
+	"0" { "path" "/default" }
+	"1" "/lib1"
+}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/default", "/lib1", "/lib2", "/lib10"}
+	for i := 0; i < 5; i++ {
+		got := steamLibraries(steam)
+		if len(got) != len(want) {
+			t.Fatalf("steamLibraries = %v, want %v", got, want)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("steamLibraries = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestDiscoverLaunchers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("launcher discovery is only done on Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	library := t.TempDir()
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	install := func(prefix, user, name string) {
+		t.Helper()
+		dir := filepath.Join(prefixSettingsPath(prefix, user), name, "settings_Default")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Steam with a second library
+	steam := filepath.Join(home, ".local", "share", "Steam")
+	write(filepath.Join(steam, "steamapps", "libraryfolders.vdf"), `"libraryfolders"
+{
+	"0" { "path" "`+steam+`" }
+	"1" { "path" "`+library+`" }
+}`)
+	install(filepath.Join(library, "steamapps", "compatdata", "8500", "pfx"), "steamuser", "c_steam_tq_tranquility")
+
+	// Lutris game with a custom prefix
+	lutrisPrefix := filepath.Join(home, "wine", "eve")
+	write(filepath.Join(home, ".config", "lutris", "games", "eve-online-1.yml"),
+		"game:\n  exe: drive_c/EVE/eve.exe\n  prefix: ~/wine/eve\nwine:\n  version: lutris-7\n")
+	write(filepath.Join(home, ".config", "lutris", "games", "other.yml"), "game:\n  prefix: /nowhere\n")
+	install(lutrisPrefix, "pilot", "c_lutris_tq_tranquility")
+
+	// Bottles
+	install(filepath.Join(home, ".local", "share", "bottles", "bottles", "EVE"), "pilot", "c_bottles_sisi_singularity")
+
+	// Heroic sideloaded game with its prefix set in the game config
+	heroicPrefix := filepath.Join(home, "heroic-eve")
+	write(filepath.Join(home, ".config", "heroic", "GamesConfig", "abc.json"),
+		`{"abc": {"winePrefix": "`+heroicPrefix+`"}, "version": "v0"}`)
+	install(heroicPrefix, "pilot", "c_heroic_tq_tranquility")
+
+	installs, err := DetectInstallations()
+	if err != nil {
+		t.Fatalf("DetectInstallations failed: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, install := range installs {
+		got[install.Name] = install.Launcher
+	}
+	want := map[string]string{
+		"c_steam_tq_tranquility":     LauncherSteam,
+		"c_lutris_tq_tranquility":    LauncherLutris,
+		"c_bottles_sisi_singularity": LauncherBottles,
+		"c_heroic_tq_tranquility":    LauncherHeroic,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d installations, got %v", len(want), got)
+	}
+	for name, launcher := range want {
+		if got[name] != launcher {
+			t.Errorf("%s: launcher = %q, want %q", name, got[name], launcher)
+		}
+	}
+}
+
+func TestSearchRootsDeduplicatesSymlinks(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Steam symlink layout is only used on Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	steam := filepath.Join(home, ".local", "share", "Steam")
+	if err := os.MkdirAll(prefixSettingsPath(filepath.Join(steam, "steamapps", "compatdata", "8500", "pfx"), "steamuser"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".steam"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(steam, filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Fatal(err)
+	}
+
+	var steamPaths []string
+	for _, root := range SearchRoots() {
+		if root.Launcher == LauncherSteam {
+			steamPaths = append(steamPaths, root.Path)
+		}
+	}
+	if len(steamPaths) != 1 {
+		t.Errorf("expected the symlinked Steam folder once, got %v", steamPaths)
+	}
+}
+
+func TestDarwinPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USER", "pilot")
+
+	appSupport := filepath.Join(home, "Library", "Application Support")
+	for _, dir := range []string{
+		filepath.Join(appSupport, "CCP", "EVE", "c_ccp_eve_tq_tranquility", "settings_Default"),
+		filepath.Join(prefixSettingsPath(filepath.Join(appSupport, "CrossOver", "Bottles", "EVE Online"), "crossover"),
+			"c_eve_sharedcache_sisi_singularity", "settings_Default"),
+		filepath.Join(prefixSettingsPath(filepath.Join(home, "Library", "Containers", "com.isaacmarovitz.Whisky",
+			"Bottles", "1234"), "crossover"), "c_whisky_tq_tranquility", "settings_Default"),
+		filepath.Join(appSupport, "CrossOver", "Bottles", "Steam", "drive_c"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots := platformRoots("darwin")
+	if len(roots) != 4 {
+		t.Errorf("expected native, CrossOver, Whisky and ~/.wine roots, got %+v", roots)
+	}
+	if roots[0].Path != filepath.Join(appSupport, "CCP", "EVE") || roots[0].Launcher != LauncherNative {
+		t.Errorf("unexpected native root %+v", roots[0])
+	}
+
+	installs, err := installationsIn(roots)
+	if err != nil {
+		t.Fatalf("installationsIn failed: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, install := range installs {
+		got[install.Name] = install.Launcher + "/" + install.Server
+	}
+	want := map[string]string{
+		"c_ccp_eve_tq_tranquility":           LauncherNative + "/" + ServerTranquility,
+		"c_eve_sharedcache_sisi_singularity": LauncherCrossOver + "/" + ServerSingularity,
+		"c_whisky_tq_tranquility":            LauncherWine + "/" + ServerTranquility,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d installations, got %v", len(want), got)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %q, want %q", name, got[name], w)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Known Eve Online servers.
+const (
+	ServerTranquility = "tranquility"
+	ServerSingularity = "singularity"
+	ServerSerenity    = "serenity"
+	ServerThunderdome = "thunderdome"
+	ServerUnknown     = "unknown"
+)
+
+// serverAliases maps the names used in installation folders and on the
+// command line to server names.
+var serverAliases = map[string]string{
+	"tranquility": ServerTranquility,
+	"tq":          ServerTranquility,
+	"singularity": ServerSingularity,
+	"sisi":        ServerSingularity,
+	"serenity":    ServerSerenity,
+	"thunderdome": ServerThunderdome,
+}
+
+// Installation is one Eve client installation as seen from its settings
+// folder, e.g. c_eve_sharedcache_tq_tranquility.
+type Installation struct {
+	Name     string   // folder name
+	Path     string   // full path of the folder
+	Server   string   // server the installation connects to
+	Launcher string   // launcher it was found through, e.g. LauncherSteam
+	Profiles []string // settings_* directories, e.g. settings_Default
+}
+
+// ServerFromFolder derives the server of an installation from its folder
+// name, e.g. "tranquility" for c_eve_sharedcache_tq_tranquility. It returns
+// ServerUnknown if the name does not mention a known server.
+func ServerFromFolder(name string) string {
+	parts := strings.Split(strings.ToLower(name), "_")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if server, ok := serverAliases[parts[i]]; ok {
+			return server
+		}
+	}
+	return ServerUnknown
+}
+
+// ParseServer returns the server named by s, accepting short names such as
+// "tq" and "sisi".
+func ParseServer(s string) (string, error) {
+	if server, ok := serverAliases[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return server, nil
+	}
+	return "", fmt.Errorf("unknown server '%s' (use tranquility, singularity, serenity or thunderdome)", s)
+}
+
+// DetectInstallations finds all Eve installations with at least one settings
+// profile. Folders that cannot be read are skipped and reported in a Warnings
+// error returned with the installations found elsewhere.
+func DetectInstallations() ([]Installation, error) {
+	return installationsIn(SearchRoots())
+}
+
+// installationsIn finds the Eve installations in the given settings base
+// paths.
+func installationsIn(roots []SearchRoot) ([]Installation, error) {
+	var installs []Installation
+	var warnings Warnings
+
+	for _, root := range roots {
+		basePath := root.Path
+		entries, err := os.ReadDir(basePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			warnings.add(basePath, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || isExcluded(filepath.Join(basePath, entry.Name())) {
+				continue
+			}
+
+			install := Installation{
+				Name:     entry.Name(),
+				Path:     filepath.Join(basePath, entry.Name()),
+				Server:   ServerFromFolder(entry.Name()),
+				Launcher: root.Launcher,
+			}
+
+			// Check for settings_* subdirectories (e.g., settings_Default)
+			profileEntries, err := os.ReadDir(install.Path)
+			if err != nil {
+				warnings.add(install.Path, err)
+				continue
+			}
+
+			for _, profileEntry := range profileEntries {
+				profilePath := filepath.Join(install.Path, profileEntry.Name())
+				if profileEntry.IsDir() && strings.HasPrefix(profileEntry.Name(), ProfilePrefix) && !isExcluded(profilePath) {
+					install.Profiles = append(install.Profiles, profilePath)
+				}
+			}
+
+			if len(install.Profiles) > 0 {
+				installs = append(installs, install)
+			}
+		}
+	}
+
+	return installs, warnings.err()
+}
+
+// FilterInstallations returns the installations connecting to server. An
+// empty server matches every installation.
+func FilterInstallations(installs []Installation, server string) []Installation {
+	if server == "" {
+		return installs
+	}
+
+	var filtered []Installation
+	for _, install := range installs {
+		if install.Server == server {
+			filtered = append(filtered, install)
+		}
+	}
+	return filtered
+}
+
+// SettingsDirectories returns the settings profile directories of installs.
+func SettingsDirectories(installs []Installation) []string {
+	var dirs []string
+	for _, install := range installs {
+		dirs = append(dirs, install.Profiles...)
+	}
+	return dirs
+}
+
+// Servers returns the distinct servers of installs, sorted by name.
+func Servers(installs []Installation) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, install := range installs {
+		if !seen[install.Server] {
+			seen[install.Server] = true
+			servers = append(servers, install.Server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// ServerOfPath returns the server of the installation containing path, or
+// ServerUnknown if no installation in installs contains it.
+func ServerOfPath(installs []Installation, path string) string {
+	for _, install := range installs {
+		if strings.HasPrefix(path, install.Path+string(filepath.Separator)) {
+			return install.Server
+		}
+	}
+	return ServerUnknown
+}
@@ -0,0 +1,160 @@
+package eve
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestServerFromFolder(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"c_eve_sharedcache_tq_tranquility", ServerTranquility},
+		{"c_ccp_eve_online_tq_tranquility", ServerTranquility},
+		{"c_eve_sharedcache_sisi_singularity", ServerSingularity},
+		{"c_eve_sharedcache_serenity_serenity", ServerSerenity},
+		{"c_eve_sharedcache_thunderdome", ServerThunderdome},
+		{"c_eve_sharedcache_TQ", ServerTranquility},
+		{"cache", ServerUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ServerFromFolder(tt.name); got != tt.want {
+				t.Errorf("ServerFromFolder(%s) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseServer(t *testing.T) {
+	if got, err := ParseServer("SiSi"); err != nil || got != ServerSingularity {
+		t.Errorf("ParseServer(SiSi) = %s, %v", got, err)
+	}
+	if _, err := ParseServer("duality"); err == nil {
+		t.Error("expected error for unknown server")
+	}
+}
+
+func TestDetectInstallations(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake Steam tree is only laid out for Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	base := filepath.Join(home, ".steam", "steam", "steamapps", "compatdata", "8500", "pfx",
+		"drive_c", "users", "steamuser", "AppData", "Local", "CCP", "EVE")
+	for _, dir := range []string{
+		filepath.Join("c_eve_sharedcache_tq_tranquility", "settings_Default"),
+		filepath.Join("c_eve_sharedcache_tq_tranquility", "settings_PvP"),
+		filepath.Join("c_eve_sharedcache_sisi_singularity", "settings_Default"),
+		filepath.Join("c_eve_sharedcache_tq_tranquility", "cache"),
+		"no_profiles",
+	} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	installs, err := DetectInstallations()
+	if err != nil {
+		t.Fatalf("DetectInstallations failed: %v", err)
+	}
+	if len(installs) != 2 {
+		t.Fatalf("expected 2 installations, got %+v", installs)
+	}
+
+	tq := FilterInstallations(installs, ServerTranquility)
+	if len(tq) != 1 || len(tq[0].Profiles) != 2 {
+		t.Errorf("expected one Tranquility install with 2 profiles, got %+v", tq)
+	}
+	if dirs := SettingsDirectories(installs); len(dirs) != 3 {
+		t.Errorf("expected 3 settings directories, got %v", dirs)
+	}
+
+	path := filepath.Join(base, "c_eve_sharedcache_sisi_singularity", "settings_Default", "core_char_1.dat")
+	if got := ServerOfPath(installs, path); got != ServerSingularity {
+		t.Errorf("ServerOfPath() = %s, want %s", got, ServerSingularity)
+	}
+	if got := ServerOfPath(installs, "/elsewhere/core_char_1.dat"); got != ServerUnknown {
+		t.Errorf("ServerOfPath() = %s, want %s", got, ServerUnknown)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	t.Cleanup(func() { SetSearchOptions(SearchOptions{}) })
+
+	base := t.TempDir()
+	root := filepath.Join(base, "mnt", "EVE")
+	prefix := filepath.Join(base, "prefix")
+	winRoot := filepath.Join(prefix, "drive_c", "users", "pilot", "AppData", "Local", "CCP", "EVE")
+	for _, dir := range []string{
+		filepath.Join(root, "c_eve_sharedcache_tq_tranquility", "settings_Default"),
+		filepath.Join(root, "c_old_tq_tranquility", "settings_Default"),
+		filepath.Join(winRoot, "c_eve_sharedcache_sisi_singularity", "settings_Default"),
+		filepath.Join(winRoot, "c_eve_sharedcache_sisi_singularity", "settings_Broken"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	SetSearchOptions(SearchOptions{
+		ExtraRoots:   []string{root, root},
+		WinePrefixes: []string{prefix},
+		Exclude: []string{
+			filepath.Join(root, "c_old_tq_tranquility"),
+			filepath.Join(winRoot, "c_eve_sharedcache_sisi_singularity", "settings_Broken"),
+		},
+	})
+
+	paths := GetPossibleSettingsPaths()
+	found := map[string]int{}
+	for _, p := range paths {
+		found[p]++
+	}
+	if found[root] != 1 || found[winRoot] != 1 {
+		t.Errorf("expected extra root and Wine prefix path once each, got %v", paths)
+	}
+
+	installs, err := DetectInstallations()
+	if err != nil {
+		t.Fatalf("DetectInstallations failed: %v", err)
+	}
+
+	var names []string
+	for _, install := range installs {
+		if strings.HasPrefix(install.Path, base) {
+			names = append(names, install.Name)
+			if len(install.Profiles) != 1 {
+				t.Errorf("%s: expected excluded profile to be skipped, got %v", install.Name, install.Profiles)
+			}
+		}
+	}
+	if len(names) != 2 {
+		t.Errorf("expected 2 installations from the extra locations, got %v", names)
+	}
+
+	SetSearchOptions(SearchOptions{Exclude: []string{base}})
+	for _, p := range GetPossibleSettingsPaths() {
+		if strings.HasPrefix(p, base) {
+			t.Errorf("excluded path %s still searched", p)
+		}
+	}
+}
+
+func TestWinePrefixPathsDefaultUser(t *testing.T) {
+	t.Setenv("USER", "pilot")
+	prefix := t.TempDir()
+
+	want := filepath.Join(prefix, "drive_c", "users", "pilot", "AppData", "Local", "CCP", "EVE")
+	if got := winePrefixPaths(prefix); len(got) != 1 || got[0] != want {
+		t.Errorf("winePrefixPaths() = %v, want [%s]", got, want)
+	}
+}
@@ -4,51 +4,192 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 )
 
-// GetPossibleSettingsPaths returns all possible Eve settings base paths for the current platform.
+// SearchOptions customises where Eve settings are searched for.
+type SearchOptions struct {
+	ExtraRoots   []string // additional Eve settings base paths
+	WinePrefixes []string // additional Wine prefixes with an Eve installation
+	Exclude      []string // directories never searched, with everything below them
+}
+
+// searchOptions are the options set with SetSearchOptions.
+var searchOptions SearchOptions
+
+// SetSearchOptions sets the extra locations searched for settings and the
+// directories excluded from the search.
+func SetSearchOptions(opts SearchOptions) {
+	searchOptions = opts
+}
+
+// SearchRoot is an Eve settings base path together with the launcher it was
+// found through.
+type SearchRoot struct {
+	Path     string
+	Launcher string
+}
+
+// GetPossibleSettingsPaths returns all possible Eve settings base paths for the
+// current platform, followed by the extra roots and Wine prefixes from the
+// search options. Excluded paths are left out.
 func GetPossibleSettingsPaths() []string {
-	switch runtime.GOOS {
+	var paths []string
+	for _, root := range SearchRoots() {
+		paths = append(paths, root.Path)
+	}
+	return paths
+}
+
+// SearchRoots returns the paths of GetPossibleSettingsPaths with the launcher
+// each one belongs to. A path reachable through several launchers or
+// symlinks is only returned once.
+func SearchRoots() []SearchRoot {
+	roots := platformRoots(runtime.GOOS)
+	for _, path := range searchOptions.ExtraRoots {
+		roots = append(roots, SearchRoot{Path: path, Launcher: LauncherCustom})
+	}
+	for _, prefix := range searchOptions.WinePrefixes {
+		roots = append(roots, prefixRoots(winePrefixPaths(prefix), LauncherCustom)...)
+	}
+
+	seen := make(map[string]bool)
+	var result []SearchRoot
+	for _, root := range roots {
+		root.Path = filepath.Clean(root.Path)
+		key := root.Path
+		if resolved, err := filepath.EvalSymlinks(key); err == nil {
+			key = resolved
+		}
+		if seen[key] || isExcluded(root.Path) {
+			continue
+		}
+		seen[key] = true
+		result = append(result, root)
+	}
+	return result
+}
+
+// isExcluded reports whether path is, or lies below, an excluded directory.
+func isExcluded(path string) bool {
+	for _, dir := range searchOptions.Exclude {
+		dir = filepath.Clean(dir)
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// winePrefixPaths returns the Eve settings base paths of every Windows user in
+// a Wine prefix, or the current user's path if none exists yet.
+func winePrefixPaths(prefix string) []string {
+	if matches := existingPrefixPaths(prefix); len(matches) > 0 {
+		return matches
+	}
+	return []string{prefixSettingsPath(prefix, wineUserName())}
+}
+
+// existingPrefixPaths returns the Eve settings base paths that exist in a
+// Wine prefix, one per Windows user who ran Eve.
+func existingPrefixPaths(prefix string) []string {
+	matches, _ := filepath.Glob(prefixSettingsPath(prefix, "*"))
+	return matches
+}
+
+// prefixSettingsPath returns the Eve settings base path of a Windows user in a
+// Wine prefix.
+func prefixSettingsPath(prefix, user string) string {
+	return filepath.Join(prefix, "drive_c", "users", user, "AppData", "Local", "CCP", "EVE")
+}
+
+// prefixRoots labels paths with launcher.
+func prefixRoots(paths []string, launcher string) []SearchRoot {
+	roots := make([]SearchRoot, len(paths))
+	for i, path := range paths {
+		roots[i] = SearchRoot{Path: path, Launcher: launcher}
+	}
+	return roots
+}
+
+// wineUserName returns the name Wine uses for the current user's profile.
+func wineUserName() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Base(home)
+	}
+	return "user"
+}
+
+// platformRoots returns the default settings base paths for an operating
+// system.
+func platformRoots(goos string) []SearchRoot {
+	switch goos {
 	case "windows":
 		return getWindowsPaths()
 	case "linux":
 		return getLinuxPaths()
-	default:
-		return nil
+	case "darwin":
+		return getDarwinPaths()
 	}
+	return nil
 }
 
-func getWindowsPaths() []string {
+func getWindowsPaths() []SearchRoot {
 	localAppData := os.Getenv("LOCALAPPDATA")
 	if localAppData == "" {
 		return nil
 	}
-	return []string{
-		filepath.Join(localAppData, "CCP", "EVE"),
+	return []SearchRoot{
+		{Path: filepath.Join(localAppData, "CCP", "EVE"), Launcher: LauncherNative},
 	}
 }
 
-func getLinuxPaths() []string {
+func getLinuxPaths() []SearchRoot {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil
 	}
 
-	paths := []string{}
+	var roots []SearchRoot
+	roots = append(roots, steamRoots(home)...)
+	roots = append(roots, lutrisRoots(home)...)
+	roots = append(roots, bottlesRoots(home)...)
+	roots = append(roots, heroicRoots(home)...)
 
-	// Steam Proton paths
-	steamPaths := []string{
-		filepath.Join(home, ".steam", "steam", "steamapps", "compatdata", "8500", "pfx", "drive_c", "users", "steamuser", "AppData", "Local", "CCP", "EVE"),
-		filepath.Join(home, ".local", "share", "Steam", "steamapps", "compatdata", "8500", "pfx", "drive_c", "users", "steamuser", "AppData", "Local", "CCP", "EVE"),
+	// Plain Wine
+	roots = append(roots, prefixRoots(winePrefixPaths(filepath.Join(home, ".wine")), LauncherWine)...)
+
+	return roots
+}
+
+func getDarwinPaths() []SearchRoot {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
 	}
-	paths = append(paths, steamPaths...)
+	appSupport := filepath.Join(home, "Library", "Application Support")
 
-	// Lutris paths - check common locations
-	lutrisPaths := []string{
-		filepath.Join(home, "Games", "eve-online", "drive_c", "users", home, "AppData", "Local", "CCP", "EVE"),
-		filepath.Join(home, ".wine", "drive_c", "users", home, "AppData", "Local", "CCP", "EVE"),
+	// Native client
+	roots := []SearchRoot{
+		{Path: filepath.Join(appSupport, "CCP", "EVE"), Launcher: LauncherNative},
 	}
-	paths = append(paths, lutrisPaths...)
 
-	return paths
+	// CrossOver bottles
+	bottles, _ := filepath.Glob(filepath.Join(appSupport, "CrossOver", "Bottles", "*"))
+	for _, bottle := range bottles {
+		roots = append(roots, prefixRoots(existingPrefixPaths(bottle), LauncherCrossOver)...)
+	}
+
+	// Wine, including Whisky bottles
+	prefixes, _ := filepath.Glob(filepath.Join(home, "Library", "Containers", "com.isaacmarovitz.Whisky",
+		"Bottles", "*"))
+	for _, prefix := range prefixes {
+		roots = append(roots, prefixRoots(existingPrefixPaths(prefix), LauncherWine)...)
+	}
+	roots = append(roots, prefixRoots(winePrefixPaths(filepath.Join(home, ".wine")), LauncherWine)...)
+
+	return roots
 }
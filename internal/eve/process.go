@@ -0,0 +1,87 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ClientRunning reports whether an Eve client is running on this computer,
+// natively or under Wine/Proton.
+func ClientRunning() (bool, error) {
+	commands, err := processCommands()
+	if err != nil {
+		return false, err
+	}
+	for _, command := range commands {
+		if isClientProcess(command) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isClientProcess reports whether a process command line is the Eve client
+// (exefile), as opposed to the launcher or anything else.
+func isClientProcess(command string) bool {
+	for _, field := range strings.Fields(strings.ToLower(command)) {
+		// Wine command lines use Windows paths
+		name := field[strings.LastIndexAny(field, `/\`)+1:]
+		if name == "exefile.exe" || name == "exefile" {
+			return true
+		}
+	}
+	return false
+}
+
+// processCommands returns the command lines of the running processes.
+func processCommands() ([]string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return procCommands()
+	case "windows":
+		out, err := exec.Command("tasklist", "/FO", "CSV", "/NH").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list processes: %w", err)
+		}
+		var commands []string
+		for _, line := range strings.Split(string(out), "\n") {
+			// "exefile.exe","1234",...
+			if fields := strings.SplitN(line, ",", 2); len(fields) > 0 {
+				commands = append(commands, strings.Trim(fields[0], `"`))
+			}
+		}
+		return commands, nil
+	default:
+		out, err := exec.Command("ps", "-axo", "command").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list processes: %w", err)
+		}
+		return strings.Split(string(out), "\n"), nil
+	}
+}
+
+// procCommands reads the command lines of the running processes from /proc.
+func procCommands() ([]string, error) {
+	entries, err := filepath.Glob("/proc/[0-9]*/cmdline")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to list processes: /proc is not available")
+	}
+
+	var commands []string
+	for _, name := range entries {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			// The process exited or belongs to another user
+			continue
+		}
+		commands = append(commands, strings.ReplaceAll(string(data), "\x00", " "))
+	}
+	return commands, nil
+}
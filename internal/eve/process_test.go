@@ -0,0 +1,20 @@
+package eve
+
+import "testing"
+
+func TestIsClientProcess(t *testing.T) {
+	tests := map[string]bool{
+		`C:\EVE\tq\bin64\exefile.exe /server:tranquility`:                                         true,
+		`Z:\home\pilot\Games\eve-online\drive_c\EVE\SharedCache\tq\bin64\exefile.exe /ssoToken=x`: true,
+		"/Applications/EVE Online.app/Contents/Resources/build/bin/exefile":                       true,
+		`C:\EVE\Launcher\evelauncher.exe`:                                                         false,
+		"/usr/bin/vim exefile_notes.txt":                                                          false,
+		"":                                                                                        false,
+	}
+
+	for command, want := range tests {
+		if got := isClientProcess(command); got != want {
+			t.Errorf("isClientProcess(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package eve
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jpbriend/eve-settings-manager/internal/backup"
+)
+
+// ProfilePrefix starts the folder name of every settings profile.
+const ProfilePrefix = "settings_"
+
+// Profile is a named settings profile folder (settings_*) of an installation.
+type Profile struct {
+	Name         string // profile name without the settings_ prefix, e.g. "Default"
+	Path         string
+	Installation *Installation
+}
+
+// ProfileName returns the name of the profile stored in dir, e.g. "PvP" for
+// .../settings_PvP.
+func ProfileName(dir string) string {
+	return strings.TrimPrefix(filepath.Base(dir), ProfilePrefix)
+}
+
+// Profiles returns the settings profiles of installs.
+func Profiles(installs []Installation) []Profile {
+	var profiles []Profile
+	for i := range installs {
+		for _, dir := range installs[i].Profiles {
+			profiles = append(profiles, Profile{
+				Name:         ProfileName(dir),
+				Path:         dir,
+				Installation: &installs[i],
+			})
+		}
+	}
+	return profiles
+}
+
+// FindProfile returns the profile among profiles that identifier names, by
+// folder path or by name (with or without the settings_ prefix). Folders that
+// are not one of profiles are refused, so that nothing else is taken for a
+// profile to rename or delete.
+func FindProfile(profiles []Profile, identifier string) (*Profile, error) {
+	if path, err := filepath.Abs(identifier); err == nil {
+		for i, p := range profiles {
+			profilePath, err := filepath.Abs(p.Path)
+			if err == nil && profilePath == path && strings.HasPrefix(filepath.Base(path), ProfilePrefix) {
+				return &profiles[i], nil
+			}
+		}
+	}
+
+	name := strings.TrimPrefix(identifier, ProfilePrefix)
+	var matches []Profile
+	for _, p := range profiles {
+		if p.Name == name {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if info, err := os.Stat(identifier); err == nil && info.IsDir() {
+			return nil, fmt.Errorf("'%s' is not a settings profile of a detected Eve installation", identifier)
+		}
+		return nil, fmt.Errorf("profile '%s' not found", name)
+	case 1:
+		return &matches[0], nil
+	}
+
+	var paths []string
+	for _, p := range matches {
+		paths = append(paths, "  "+p.Path)
+	}
+	return nil, fmt.Errorf("profile '%s' exists in several installations; use --server or give its path:\n%s",
+		name, strings.Join(paths, "\n"))
+}
+
+// FilterProfiles returns installs with only their profiles named name (with
+// or without the settings_ prefix). Installations without such a profile are
+// left out. An empty name matches every profile.
+func FilterProfiles(installs []Installation, name string) []Installation {
+	if name == "" {
+		return installs
+	}
+	name = strings.TrimPrefix(name, ProfilePrefix)
+
+	var filtered []Installation
+	for _, install := range installs {
+		var profiles []string
+		for _, dir := range install.Profiles {
+			if ProfileName(dir) == name {
+				profiles = append(profiles, dir)
+			}
+		}
+		if len(profiles) > 0 {
+			install.Profiles = profiles
+			filtered = append(filtered, install)
+		}
+	}
+	return filtered
+}
+
+// ValidateProfileName checks that name can be used as a profile name.
+func ValidateProfileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\:*?"<>|`) {
+		return fmt.Errorf("invalid profile name '%s'", name)
+	}
+	return nil
+}
+
+// ProfilePath returns the folder of the profile called name next to the
+// profile folder dir.
+func ProfilePath(dir, name string) string {
+	return filepath.Join(filepath.Dir(dir), ProfilePrefix+strings.TrimPrefix(name, ProfilePrefix))
+}
+
+// CloneProfile copies the profile folder src to a new profile called name in
+// the same installation and returns its path. Backups made by esm in src are
+// not copied.
+func CloneProfile(src, name string) (string, error) {
+	name = strings.TrimPrefix(name, ProfilePrefix)
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+
+	dst := ProfilePath(src, name)
+	if _, err := os.Stat(dst); err == nil {
+		return "", fmt.Errorf("profile '%s' already exists", name)
+	}
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if backup.IsBackup(d.Name()) {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+	if err != nil {
+		_ = os.RemoveAll(dst)
+		return "", fmt.Errorf("failed to clone profile: %w", err)
+	}
+
+	return dst, nil
+}
+
+// RenameProfile renames the profile folder dir to name and returns its new
+// path.
+func RenameProfile(dir, name string) (string, error) {
+	name = strings.TrimPrefix(name, ProfilePrefix)
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+
+	dst := ProfilePath(dir, name)
+	if _, err := os.Stat(dst); err == nil {
+		return "", fmt.Errorf("profile '%s' already exists", name)
+	}
+
+	if err := os.Rename(dir, dst); err != nil {
+		return "", fmt.Errorf("failed to rename profile: %w", err)
+	}
+	return dst, nil
+}
+
+// FileStatus describes how a file differs between two profile folders.
+type FileStatus string
+
+const (
+	// FileOnlyInFirst means the file only exists in the first profile.
+	FileOnlyInFirst FileStatus = "only-in-first"
+	// FileOnlyInSecond means the file only exists in the second profile.
+	FileOnlyInSecond FileStatus = "only-in-second"
+	// FileDiffers means the file exists in both profiles with different contents.
+	FileDiffers FileStatus = "differs"
+)
+
+// FileDifference is a file that differs between two profile folders.
+type FileDifference struct {
+	Name   string // path relative to the profile folder
+	Status FileStatus
+}
+
+// CompareProfiles compares the files of two profile folders and returns the
+// differences, sorted by file name.
+func CompareProfiles(first, second string) ([]FileDifference, error) {
+	firstFiles, err := profileFiles(first)
+	if err != nil {
+		return nil, err
+	}
+	secondFiles, err := profileFiles(second)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FileDifference
+	for name := range firstFiles {
+		if !secondFiles[name] {
+			diffs = append(diffs, FileDifference{Name: name, Status: FileOnlyInFirst})
+			continue
+		}
+
+		a, err := os.ReadFile(filepath.Join(first, name))
+		if err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(filepath.Join(second, name))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(a, b) {
+			diffs = append(diffs, FileDifference{Name: name, Status: FileDiffers})
+		}
+	}
+	for name := range secondFiles {
+		if !firstFiles[name] {
+			diffs = append(diffs, FileDifference{Name: name, Status: FileOnlyInSecond})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs, nil
+}
+
+// profileFiles returns the relative paths of all files in a profile folder.
+func profileFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", dir, err)
+	}
+	return files, nil
+}
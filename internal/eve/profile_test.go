@@ -0,0 +1,186 @@
+package eve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeProfile(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	installs := []Installation{{
+		Name:     "c_eve_sharedcache_tq_tranquility",
+		Server:   ServerTranquility,
+		Profiles: []string{filepath.Join("eve", "settings_Default"), filepath.Join("eve", "settings_PvP")},
+	}}
+
+	profiles := Profiles(installs)
+	if len(profiles) != 2 || profiles[1].Name != "PvP" || profiles[1].Installation.Server != ServerTranquility {
+		t.Errorf("unexpected profiles: %+v", profiles)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"PvP", "Mining Fleet", "alt-2"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("ValidateProfileName(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "..", "a/b", `a\b`} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
+
+func TestCloneAndRenameProfile(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "settings_Default")
+	writeProfile(t, src, map[string]string{
+		"core_char_1.dat":                   "char",
+		"sub/core_user_2.dat":               "user",
+		"backup_1_20240101_120000.zip":      "zip",
+		"backup_user_2_20240101_120000.zip": "zip",
+		"eve-backup-20240101-120000.zip":    "zip",
+	})
+
+	clone, err := CloneProfile(src, "settings_PvP")
+	if err != nil {
+		t.Fatalf("CloneProfile failed: %v", err)
+	}
+	if clone != filepath.Join(base, "settings_PvP") {
+		t.Errorf("clone path = %s", clone)
+	}
+	if data, err := os.ReadFile(filepath.Join(clone, "sub", "core_user_2.dat")); err != nil || string(data) != "user" {
+		t.Errorf("cloned file missing or wrong: %s, %v", data, err)
+	}
+	for _, name := range []string{"backup_1_20240101_120000.zip", "backup_user_2_20240101_120000.zip", "eve-backup-20240101-120000.zip"} {
+		if _, err := os.Stat(filepath.Join(clone, name)); !os.IsNotExist(err) {
+			t.Errorf("backup %s should not be cloned", name)
+		}
+	}
+	if _, err := CloneProfile(src, "PvP"); err == nil {
+		t.Error("expected error cloning onto an existing profile")
+	}
+
+	renamed, err := RenameProfile(clone, "Mining")
+	if err != nil {
+		t.Fatalf("RenameProfile failed: %v", err)
+	}
+	if _, err := os.Stat(clone); !os.IsNotExist(err) {
+		t.Error("old profile folder should be gone")
+	}
+	if ProfileName(renamed) != "Mining" {
+		t.Errorf("renamed profile = %s", renamed)
+	}
+	if _, err := RenameProfile(renamed, "Default"); err == nil {
+		t.Error("expected error renaming onto an existing profile")
+	}
+}
+
+func TestCompareProfiles(t *testing.T) {
+	base := t.TempDir()
+	first := filepath.Join(base, "settings_A")
+	second := filepath.Join(base, "settings_B")
+	writeProfile(t, first, map[string]string{"same.dat": "x", "changed.dat": "1", "gone.dat": "g"})
+	writeProfile(t, second, map[string]string{"same.dat": "x", "changed.dat": "2", "new.dat": "n"})
+
+	diffs, err := CompareProfiles(first, second)
+	if err != nil {
+		t.Fatalf("CompareProfiles failed: %v", err)
+	}
+
+	want := []FileDifference{
+		{Name: "changed.dat", Status: FileDiffers},
+		{Name: "gone.dat", Status: FileOnlyInFirst},
+		{Name: "new.dat", Status: FileOnlyInSecond},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("diffs = %+v, want %+v", diffs, want)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("diffs[%d] = %+v, want %+v", i, diffs[i], want[i])
+		}
+	}
+}
+
+func TestFilterProfiles(t *testing.T) {
+	installs := []Installation{
+		{Name: "tq", Profiles: []string{"/eve/tq/settings_Default", "/eve/tq/settings_PvP"}},
+		{Name: "sisi", Profiles: []string{"/eve/sisi/settings_Default"}},
+	}
+
+	pvp := FilterProfiles(installs, "settings_PvP")
+	if len(pvp) != 1 || pvp[0].Name != "tq" || len(pvp[0].Profiles) != 1 {
+		t.Errorf("FilterProfiles(PvP) = %+v", pvp)
+	}
+	if all := FilterProfiles(installs, ""); len(all) != 2 || len(all[0].Profiles) != 2 {
+		t.Errorf("FilterProfiles(\"\") = %+v", all)
+	}
+	if len(installs[0].Profiles) != 2 {
+		t.Error("FilterProfiles modified its input")
+	}
+}
+
+func TestFindProfile(t *testing.T) {
+	base := t.TempDir()
+	tq := filepath.Join(base, "tq")
+	sisi := filepath.Join(base, "sisi")
+	installs := []Installation{
+		{Name: "tq", Profiles: []string{filepath.Join(tq, "settings_Default"), filepath.Join(tq, "settings_PvP")}},
+		{Name: "sisi", Profiles: []string{filepath.Join(sisi, "settings_Default")}},
+	}
+	for _, install := range installs {
+		for _, dir := range install.Profiles {
+			writeProfile(t, dir, map[string]string{"core_char_1.dat": "char"})
+		}
+	}
+	profiles := Profiles(installs)
+
+	for _, identifier := range []string{"PvP", "settings_PvP", filepath.Join(tq, "settings_PvP")} {
+		if p, err := FindProfile(profiles, identifier); err != nil || p.Path != filepath.Join(tq, "settings_PvP") {
+			t.Errorf("FindProfile(%q) = %+v, %v", identifier, p, err)
+		}
+	}
+	if p, err := FindProfile(profiles, filepath.Join(sisi, "settings_Default")); err != nil || p.Installation.Name != "sisi" {
+		t.Errorf("FindProfile(sisi path) = %+v, %v", p, err)
+	}
+	if _, err := FindProfile(profiles, "Default"); err == nil {
+		t.Error("expected an error for a profile in several installations")
+	}
+	if _, err := FindProfile(profiles, "Mining"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestFindProfileRefusesOtherFolders(t *testing.T) {
+	base := t.TempDir()
+	profile := filepath.Join(base, "tq", "settings_Default")
+	victim := filepath.Join(base, "victim")
+	writeProfile(t, profile, map[string]string{"core_char_1.dat": "char"})
+	writeProfile(t, victim, map[string]string{"notes.txt": "keep me"})
+	writeProfile(t, filepath.Join(base, "settings_Stray"), map[string]string{"core_char_2.dat": "char"})
+	profiles := Profiles([]Installation{{Name: "tq", Profiles: []string{profile}}})
+
+	// Folders named by path, or by a bare name relative to the working
+	// directory, are not profiles unless detected as one
+	t.Chdir(base)
+	for _, identifier := range []string{victim, "victim", ".", base, "settings_Stray", filepath.Join(base, "settings_Stray")} {
+		if p, err := FindProfile(profiles, identifier); err == nil {
+			t.Errorf("FindProfile(%q) = %+v, expected an error", identifier, p)
+		}
+	}
+}
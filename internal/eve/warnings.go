@@ -0,0 +1,67 @@
+package eve
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Warning is a file system problem met while searching for settings. The
+// search carries on without the path.
+type Warning struct {
+	Path string
+	Err  error
+}
+
+func (w Warning) Error() string {
+	return fmt.Sprintf("%s: %v", w.Path, w.Err)
+}
+
+func (w Warning) Unwrap() error {
+	return w.Err
+}
+
+// Warnings collects the problems met during one search. Functions return it
+// as their error together with everything found elsewhere, so a Warnings
+// error does not make the results invalid.
+type Warnings []Warning
+
+func (ws Warnings) Error() string {
+	msgs := make([]string, len(ws))
+	for i, w := range ws {
+		msgs[i] = w.Error()
+	}
+	return fmt.Sprintf("%d path(s) could not be searched: %s", len(ws), strings.Join(msgs, "; "))
+}
+
+// add records a problem with path.
+func (ws *Warnings) add(path string, err error) {
+	*ws = append(*ws, Warning{Path: path, Err: err})
+}
+
+// err returns ws as an error, or nil if there were no problems.
+func (ws Warnings) err() error {
+	if len(ws) == 0 {
+		return nil
+	}
+	return ws
+}
+
+// IsWarnings reports whether err only holds Warnings, i.e. whether the
+// results returned with it are usable.
+func IsWarnings(err error) bool {
+	var ws Warnings
+	return errors.As(err, &ws)
+}
+
+// joinWarnings combines the Warnings errors of several searches.
+func joinWarnings(errs ...error) error {
+	var all Warnings
+	for _, err := range errs {
+		var ws Warnings
+		if errors.As(err, &ws) {
+			all = append(all, ws...)
+		}
+	}
+	return all.err()
+}
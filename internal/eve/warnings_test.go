@@ -0,0 +1,82 @@
+package eve
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindCharacterSettingsWarnings(t *testing.T) {
+	good := t.TempDir()
+	if err := os.WriteFile(filepath.Join(good, "core_char_1.dat"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// An ID too large for int64
+	if err := os.WriteFile(filepath.Join(good, "core_char_99999999999999999999.dat"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A settings folder that is a file
+	notDir := filepath.Join(good, "core_char_1.dat")
+
+	chars, err := FindCharacterSettings([]string{good, notDir})
+	if len(chars) != 1 || chars[0].CharacterID != 1 {
+		t.Errorf("expected the readable character to be found, got %+v", chars)
+	}
+
+	var warnings Warnings
+	if !errors.As(err, &warnings) || !IsWarnings(err) {
+		t.Fatalf("expected Warnings, got %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	if warnings[1].Path != notDir || warnings[1].Err == nil {
+		t.Errorf("unexpected warning %+v", warnings[1])
+	}
+	if !strings.Contains(err.Error(), "2 path(s)") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	if _, err := FindCharacterSettings([]string{t.TempDir()}); err != nil {
+		t.Errorf("expected no error for a clean folder, got %v", err)
+	}
+}
+
+func TestInstallationsInWarnings(t *testing.T) {
+	base := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(base, "c_tq_tranquility", "settings_Default"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notDir := filepath.Join(base, "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installs, err := installationsIn([]SearchRoot{
+		{Path: base},
+		{Path: notDir},
+		{Path: filepath.Join(base, "missing")},
+	})
+	if len(installs) != 1 {
+		t.Errorf("expected 1 installation, got %+v", installs)
+	}
+
+	var warnings Warnings
+	if !errors.As(err, &warnings) || len(warnings) != 1 || warnings[0].Path != notDir {
+		t.Errorf("expected one warning for %s, got %v", notDir, err)
+	}
+}
+
+func TestIsWarnings(t *testing.T) {
+	if IsWarnings(nil) || IsWarnings(errors.New("boom")) {
+		t.Error("plain errors are not warnings")
+	}
+	if err := joinWarnings(nil, Warnings{{Path: "a", Err: os.ErrPermission}}); !IsWarnings(err) {
+		t.Errorf("joinWarnings() = %v", err)
+	}
+	if err := joinWarnings(nil, errors.New("boom")); err != nil {
+		t.Errorf("joinWarnings() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,214 @@
+package eve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// SettingsChange is a settings file written (or removed) by the Eve client.
+type SettingsChange struct {
+	Path        string
+	CharacterID int64 // set for core_char_*.dat files
+	UserID      int64 // set for core_user_*.dat files
+	Size        int64
+	PrevSize    int64 // -1 if the file did not exist before
+	Removed     bool
+	Time        time.Time
+}
+
+// Watcher reports changes to the character and account user settings files
+// in a set of directories. The client writes a file in several steps, so a
+// change is only reported once the file has been left alone for the settle
+// time.
+type Watcher struct {
+	Changes <-chan SettingsChange
+	Errors  <-chan error
+
+	fsw        *fsnotify.Watcher
+	settle     time.Duration
+	changes    chan SettingsChange
+	errors     chan error
+	settled    chan settledFile
+	done       chan struct{}
+	sizes      map[string]int64
+	timers     map[string]settleTimer
+	generation uint64
+}
+
+// settleTimer is the settle timer of a file. A timer that fired just before
+// being restarted cannot be stopped any more, so the generation tells its
+// settledFile from the one of the timer replacing it.
+type settleTimer struct {
+	timer      *time.Timer
+	generation uint64
+}
+
+// settledFile is sent by a settle timer once it fires.
+type settledFile struct {
+	path       string
+	generation uint64
+}
+
+// NewWatcher starts watching dirs. Changes are delivered on the Changes
+// channel until Close is called.
+func NewWatcher(dirs []string, settle time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		fsw:     fsw,
+		settle:  settle,
+		changes: make(chan SettingsChange),
+		errors:  make(chan error),
+		settled: make(chan settledFile),
+		done:    make(chan struct{}),
+		sizes:   make(map[string]int64),
+		timers:  make(map[string]settleTimer),
+	}
+	w.Changes = w.changes
+	w.Errors = w.errors
+
+	for _, dir := range dirs {
+		if err := fsw.Add(dir); err != nil {
+			_ = fsw.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if _, _, ok := settingsFileID(entry.Name()); ok {
+				if info, err := entry.Info(); err == nil {
+					w.sizes[filepath.Join(dir, entry.Name())] = info.Size()
+				}
+			}
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching and closes the Changes and Errors channels.
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.fsw.Close()
+}
+
+func (w *Watcher) run() {
+	defer close(w.changes)
+	defer close(w.errors)
+	defer func() {
+		for _, t := range w.timers {
+			t.timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if _, _, ok := settingsFileID(filepath.Base(event.Name)); !ok || event.Op == fsnotify.Chmod {
+				continue
+			}
+			w.schedule(event.Name)
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case w.errors <- err:
+			case <-w.done:
+				return
+			}
+
+		case s := <-w.settled:
+			if t, ok := w.timers[s.path]; !ok || t.generation != s.generation {
+				// Restarted since it fired; the file is reported by its new timer
+				continue
+			}
+			delete(w.timers, s.path)
+			select {
+			case w.changes <- w.change(s.path):
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// schedule (re)starts the settle timer of path.
+func (w *Watcher) schedule(path string) {
+	if t, ok := w.timers[path]; ok {
+		t.timer.Stop()
+	}
+
+	w.generation++
+	generation := w.generation
+	w.timers[path] = settleTimer{
+		timer: time.AfterFunc(w.settle, func() {
+			select {
+			case w.settled <- settledFile{path: path, generation: generation}:
+			case <-w.done:
+			}
+		}),
+		generation: generation,
+	}
+}
+
+// change describes the current state of path and remembers its size.
+func (w *Watcher) change(path string) SettingsChange {
+	kind, id, _ := settingsFileID(filepath.Base(path))
+	c := SettingsChange{Path: path, PrevSize: -1, Time: time.Now()}
+	if kind == "char" {
+		c.CharacterID = id
+	} else {
+		c.UserID = id
+	}
+
+	if size, ok := w.sizes[path]; ok {
+		c.PrevSize = size
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		c.Removed = true
+		delete(w.sizes, path)
+		return c
+	}
+	c.Size = info.Size()
+	c.Time = info.ModTime()
+	w.sizes[path] = c.Size
+	return c
+}
+
+// settingsFileID returns the kind ("char" or "user") and ID of a settings
+// file name.
+func settingsFileID(name string) (string, int64, bool) {
+	kind := "char"
+	matches := charFilePattern.FindStringSubmatch(name)
+	if matches == nil {
+		kind = "user"
+		matches = userFilePattern.FindStringSubmatch(name)
+	}
+	if matches == nil {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return kind, id, true
+}
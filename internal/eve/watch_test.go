@@ -0,0 +1,110 @@
+package eve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	charPath := filepath.Join(dir, "core_char_1.dat")
+	if err := os.WriteFile(charPath, []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher([]string{dir}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	next := func() SettingsChange {
+		t.Helper()
+		select {
+		case c := <-w.Changes:
+			return c
+		case err := <-w.Errors:
+			t.Fatalf("watch error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change reported")
+		}
+		return SettingsChange{}
+	}
+
+	// Several writes in a row are reported once; other files are ignored
+	if err := os.WriteFile(filepath.Join(dir, "prefs.ini"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"1234567890", "12345678901234567890"} {
+		if err := os.WriteFile(charPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := next()
+	if c.Path != charPath || c.CharacterID != 1 || c.Size != 20 || c.PrevSize != 5 || c.Removed {
+		t.Errorf("unexpected change %+v", c)
+	}
+
+	userPath := filepath.Join(dir, "core_user_7.dat")
+	if err := os.WriteFile(userPath, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c = next()
+	if c.UserID != 7 || c.Size != 3 || c.PrevSize != -1 {
+		t.Errorf("unexpected change %+v", c)
+	}
+
+	if err := os.Remove(userPath); err != nil {
+		t.Fatal(err)
+	}
+	if c = next(); !c.Removed || c.PrevSize != 3 {
+		t.Errorf("unexpected change %+v", c)
+	}
+}
+
+func TestWatcherIgnoresStaleTimer(t *testing.T) {
+	dir := t.TempDir()
+	charPath := filepath.Join(dir, "core_char_1.dat")
+
+	w, err := NewWatcher([]string{dir}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	if err := os.WriteFile(charPath, []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// A timer that fired before the write restarted it delivers late
+	w.settled <- settledFile{path: charPath, generation: 0}
+	select {
+	case c := <-w.Changes:
+		t.Errorf("expected a stale timer to be ignored, got %+v", c)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSettingsFileID(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		id   int64
+		ok   bool
+	}{
+		{"core_char_123.dat", "char", 123, true},
+		{"core_user_45.dat", "user", 45, true},
+		{"core_char_123.dat.bak", "", 0, false},
+		{"core_char_99999999999999999999.dat", "", 0, false},
+		{"prefs.ini", "", 0, false},
+	}
+	for _, tt := range tests {
+		kind, id, ok := settingsFileID(tt.name)
+		if kind != tt.kind || id != tt.id || ok != tt.ok {
+			t.Errorf("settingsFileID(%s) = %s, %d, %v", tt.name, kind, id, ok)
+		}
+	}
+}
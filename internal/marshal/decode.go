@@ -0,0 +1,512 @@
+package marshal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// Opcodes of the encoded objects. The low six bits of each object's leading
+// byte hold the opcode; flagShared marks objects stored in the shared table.
+const (
+	opNone             = 0x01
+	opGlobal           = 0x02
+	opLongLong         = 0x03
+	opLong             = 0x04
+	opShort            = 0x05
+	opByte             = 0x06
+	opMinusOne         = 0x07
+	opZero             = 0x08
+	opOne              = 0x09
+	opReal             = 0x0A
+	opZeroReal         = 0x0B
+	opBuffer           = 0x0D
+	opEmptyString      = 0x0E
+	opCharString       = 0x0F
+	opShortString      = 0x10
+	opStringTable      = 0x11
+	opWStringUCS2      = 0x12
+	opLongString       = 0x13
+	opTuple            = 0x14
+	opList             = 0x15
+	opDict             = 0x16
+	opObject           = 0x17
+	opSubStruct        = 0x19
+	opSavedElement     = 0x1B
+	opChecksumedStream = 0x1C
+	opTrue             = 0x1F
+	opFalse            = 0x20
+	opPickled          = 0x21
+	opObjectEx1        = 0x22
+	opObjectEx2        = 0x23
+	opEmptyTuple       = 0x24
+	opOneTuple         = 0x25
+	opEmptyList        = 0x26
+	opOneList          = 0x27
+	opEmptyWString     = 0x28
+	opWStringUCS2Char  = 0x29
+	opPackedRow        = 0x2A
+	opSubStream        = 0x2B
+	opTwoTuple         = 0x2C
+	opPackedTerminator = 0x2D
+	opWStringUTF8      = 0x2E
+	opVarInteger       = 0x2F
+
+	opMask     = 0x3F
+	flagShared = 0x40
+
+	headerByte = 0x7E
+)
+
+// Unmarshal decodes a marshal stream into a Value.
+func Unmarshal(data []byte) (Value, error) {
+	if len(data) < 5 || data[0] != headerByte {
+		return nil, fmt.Errorf("marshal: missing stream header")
+	}
+
+	count := binary.LittleEndian.Uint32(data[1:5])
+	tableSize := uint64(count) * 4
+	if tableSize > uint64(len(data)-5) {
+		return nil, fmt.Errorf("marshal: shared object table larger than stream")
+	}
+
+	end := len(data) - int(tableSize)
+	d := &decoder{
+		data:  data[:end],
+		pos:   5,
+		slots: make([]int32, count),
+		saved: make(map[int32]*Shared, count),
+	}
+	for i := range d.slots {
+		d.slots[i] = int32(binary.LittleEndian.Uint32(data[end+4*i:]))
+	}
+
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("marshal: %d bytes of trailing data at offset %d", len(d.data)-d.pos, d.pos)
+	}
+	if d.next != len(d.slots) {
+		return nil, fmt.Errorf("marshal: header declares %d shared objects, stream has %d", len(d.slots), d.next)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data  []byte
+	pos   int
+	slots []int32 // shared table slot of each shared object, in stream order
+	next  int     // index of the next unused entry in slots
+	saved map[int32]*Shared
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("marshal: offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, d.errorf("unexpected end of stream")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// size reads a length: one byte, or 0xFF followed by a uint32.
+func (d *decoder) size() (int, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	if b != 0xFF {
+		return int(b), nil
+	}
+	n, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n) > uint64(len(d.data)) {
+		return 0, d.errorf("length %d exceeds stream size", n)
+	}
+	return int(n), nil
+}
+
+func (d *decoder) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, d.errorf("unexpected end of stream")
+	}
+	return d.data[d.pos], nil
+}
+
+func (d *decoder) value() (Value, error) {
+	b, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	op := b & opMask
+
+	if b&flagShared == 0 {
+		return d.object(op)
+	}
+
+	// The shared table slot is claimed before the object's contents are
+	// decoded, so containers may refer to themselves.
+	if d.next >= len(d.slots) {
+		return nil, d.errorf("more shared objects than declared in header")
+	}
+	shared := &Shared{slot: d.slots[d.next]}
+	d.next++
+	d.saved[shared.slot] = shared
+
+	v, err := d.object(op)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := v.(Ref); ok {
+		return nil, d.errorf("shared flag on a shared object reference")
+	}
+	shared.Value = v
+	return shared, nil
+}
+
+func (d *decoder) object(op byte) (Value, error) {
+	switch op {
+	case opNone:
+		return None{}, nil
+	case opTrue:
+		return Bool(true), nil
+	case opFalse:
+		return Bool(false), nil
+
+	case opMinusOne:
+		return Int{Value: -1, op: op}, nil
+	case opZero:
+		return Int{Value: 0, op: op}, nil
+	case opOne:
+		return Int{Value: 1, op: op}, nil
+	case opByte:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return Int{Value: int64(int8(b[0])), op: op}, nil
+	case opShort:
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return Int{Value: int64(int16(binary.LittleEndian.Uint16(b))), op: op}, nil
+	case opLong:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return Int{Value: int64(int32(binary.LittleEndian.Uint32(b))), op: op}, nil
+	case opLongLong:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return Int{Value: int64(binary.LittleEndian.Uint64(b)), op: op}, nil
+	case opVarInteger:
+		n, err := d.size()
+		if err != nil {
+			return nil, err
+		}
+		if n > 8 {
+			return nil, d.errorf("integer of %d bytes is not supported", n)
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		var u uint64
+		for i := n - 1; i >= 0; i-- {
+			u = u<<8 | uint64(b[i])
+		}
+		// Sign-extend from the most significant encoded byte
+		if n > 0 && n < 8 && b[n-1]&0x80 != 0 {
+			u |= ^uint64(0) << (8 * n)
+		}
+		return Int{Value: int64(u), op: op, size: n}, nil
+
+	case opReal:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return Float{Value: math.Float64frombits(binary.LittleEndian.Uint64(b)), op: op}, nil
+	case opZeroReal:
+		return Float{Value: 0, op: op}, nil
+
+	case opEmptyString:
+		return String{op: op}, nil
+	case opCharString:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return String{Value: string(b), op: op}, nil
+	case opShortString:
+		n, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return String{Value: string(b), op: op}, nil
+	case opLongString:
+		b, err := d.sized()
+		if err != nil {
+			return nil, err
+		}
+		return String{Value: string(b), op: op}, nil
+	case opStringTable:
+		b, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		return TableString(b), nil
+	case opGlobal:
+		b, err := d.sized()
+		if err != nil {
+			return nil, err
+		}
+		return Global(b), nil
+	case opBuffer:
+		b, err := d.sized()
+		if err != nil {
+			return nil, err
+		}
+		return Buffer(append([]byte(nil), b...)), nil
+
+	case opEmptyWString:
+		return Unicode{op: op}, nil
+	case opWStringUCS2Char:
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return Unicode{Value: decodeUCS2(b), op: op}, nil
+	case opWStringUCS2:
+		n, err := d.size()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(2 * n)
+		if err != nil {
+			return nil, err
+		}
+		return Unicode{Value: decodeUCS2(b), op: op}, nil
+	case opWStringUTF8:
+		b, err := d.sized()
+		if err != nil {
+			return nil, err
+		}
+		return Unicode{Value: string(b), op: op}, nil
+
+	case opEmptyTuple:
+		return &Tuple{Items: []Value{}, op: op}, nil
+	case opOneTuple:
+		items, err := d.values(1)
+		return &Tuple{Items: items, op: op}, err
+	case opTwoTuple:
+		items, err := d.values(2)
+		return &Tuple{Items: items, op: op}, err
+	case opTuple:
+		n, err := d.size()
+		if err != nil {
+			return nil, err
+		}
+		items, err := d.values(n)
+		return &Tuple{Items: items, op: op}, err
+
+	case opEmptyList:
+		return &List{Items: []Value{}, op: op}, nil
+	case opOneList:
+		items, err := d.values(1)
+		return &List{Items: items, op: op}, err
+	case opList:
+		n, err := d.size()
+		if err != nil {
+			return nil, err
+		}
+		items, err := d.values(n)
+		return &List{Items: items, op: op}, err
+
+	case opDict:
+		n, err := d.size()
+		if err != nil {
+			return nil, err
+		}
+		dict := &Dict{Entries: make([]Entry, 0, n)}
+		for i := 0; i < n; i++ {
+			// Dict entries are encoded value first
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			key, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			dict.Entries = append(dict.Entries, Entry{Key: key, Value: value})
+		}
+		return dict, nil
+
+	case opObject:
+		typ, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		args, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		return &Object{Type: typ, Args: args}, nil
+
+	case opObjectEx1, opObjectEx2:
+		return d.objectEx(op == opObjectEx2)
+
+	case opSubStruct:
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		return &SubStruct{Value: v}, nil
+
+	case opSubStream:
+		b, err := d.sized()
+		if err != nil {
+			return nil, err
+		}
+		return SubStream(append([]byte(nil), b...)), nil
+
+	case opChecksumedStream:
+		sum, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		return &Checksum{Sum: sum, Value: v}, nil
+
+	case opSavedElement:
+		slot, err := d.size()
+		if err != nil {
+			return nil, err
+		}
+		target, ok := d.saved[int32(slot)]
+		if !ok {
+			return nil, d.errorf("reference to unknown shared object %d", slot)
+		}
+		return Ref{Target: target}, nil
+
+	case opPackedRow, opPickled:
+		return nil, d.errorf("opcode 0x%02x is not supported", op)
+	}
+
+	return nil, d.errorf("invalid opcode 0x%02x", op)
+}
+
+// sized reads a length followed by that many bytes.
+func (d *decoder) sized() ([]byte, error) {
+	n, err := d.size()
+	if err != nil {
+		return nil, err
+	}
+	return d.read(n)
+}
+
+func (d *decoder) values(n int) ([]Value, error) {
+	if n > len(d.data)-d.pos {
+		return nil, d.errorf("sequence of %d items exceeds stream size", n)
+	}
+	items := make([]Value, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+func (d *decoder) objectEx(reduce bool) (Value, error) {
+	header, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	obj := &ObjectEx{Header: header, Reduce: reduce}
+
+	for {
+		b, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		if b == opPackedTerminator {
+			d.pos++
+			break
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj.List = append(obj.List, v)
+	}
+
+	for {
+		b, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		if b == opPackedTerminator {
+			d.pos++
+			break
+		}
+		key, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj.Dict = append(obj.Dict, Entry{Key: key, Value: value})
+	}
+
+	return obj, nil
+}
+
+func decodeUCS2(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
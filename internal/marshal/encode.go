@@ -0,0 +1,385 @@
+package marshal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// Marshal encodes v as a marshal stream.
+//
+// Shared objects keep their original table slots when the tree still uses
+// them consistently; otherwise all slots are renumbered in stream order.
+// A Ref whose target has not been encoded yet, for instance one transplanted
+// from another stream, is encoded as the shared object itself.
+func Marshal(v Value) ([]byte, error) {
+	e := &encoder{}
+	if err := e.collect(v, make(map[*Shared]bool)); err != nil {
+		return nil, err
+	}
+
+	e.slots = make(map[*Shared]int32, len(e.order))
+	used := make(map[int32]bool, len(e.order))
+	for _, s := range e.order {
+		if s.slot < 1 || int(s.slot) > len(e.order) || used[s.slot] {
+			e.slots = nil
+			break
+		}
+		used[s.slot] = true
+		e.slots[s] = s.slot
+	}
+	if e.slots == nil {
+		e.slots = make(map[*Shared]int32, len(e.order))
+		for i, s := range e.order {
+			e.slots[s] = int32(i + 1)
+		}
+	}
+
+	e.buf = append(e.buf, headerByte)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(len(e.order)))
+	e.emitted = make(map[*Shared]bool, len(e.order))
+	if err := e.value(v); err != nil {
+		return nil, err
+	}
+	for _, s := range e.order {
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(e.slots[s]))
+	}
+
+	return e.buf, nil
+}
+
+type encoder struct {
+	buf     []byte
+	order   []*Shared // shared objects in stream order
+	slots   map[*Shared]int32
+	emitted map[*Shared]bool
+}
+
+// collect records the shared objects of v in the order they will be encoded.
+func (e *encoder) collect(v Value, seen map[*Shared]bool) error {
+	switch t := v.(type) {
+	case *Shared:
+		if seen[t] {
+			return nil
+		}
+		switch t.Value.(type) {
+		case *Shared, Ref, nil:
+			return fmt.Errorf("marshal: shared object must wrap a plain value")
+		}
+		seen[t] = true
+		e.order = append(e.order, t)
+		return e.collect(t.Value, seen)
+	case Ref:
+		if t.Target == nil {
+			return fmt.Errorf("marshal: reference to nil shared object")
+		}
+		return e.collect(t.Target, seen)
+	}
+
+	return forEachChild(v, func(child Value) error {
+		return e.collect(child, seen)
+	})
+}
+
+// forEachChild calls fn for every directly contained value in encoding order.
+func forEachChild(v Value, fn func(Value) error) error {
+	var children []Value
+	switch t := v.(type) {
+	case *Tuple:
+		children = t.Items
+	case *List:
+		children = t.Items
+	case *Dict:
+		for _, entry := range t.Entries {
+			children = append(children, entry.Value, entry.Key)
+		}
+	case *Object:
+		children = []Value{t.Type, t.Args}
+	case *ObjectEx:
+		children = append(children, t.Header)
+		children = append(children, t.List...)
+		for _, entry := range t.Dict {
+			children = append(children, entry.Key, entry.Value)
+		}
+	case *SubStruct:
+		children = []Value{t.Value}
+	case *Checksum:
+		children = []Value{t.Value}
+	}
+
+	for _, child := range children {
+		if err := fn(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) op(op byte) {
+	e.buf = append(e.buf, op)
+}
+
+func (e *encoder) size(n int) {
+	if n < 0xFF {
+		e.buf = append(e.buf, byte(n))
+		return
+	}
+	e.buf = append(e.buf, 0xFF)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(n))
+}
+
+func (e *encoder) sized(b []byte) {
+	e.size(len(b))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) values(items []Value) error {
+	for _, item := range items {
+		if err := e.value(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) value(v Value) error {
+	switch t := v.(type) {
+	case *Shared:
+		if e.emitted[t] {
+			e.op(opSavedElement)
+			e.size(int(e.slots[t]))
+			return nil
+		}
+		e.emitted[t] = true
+		start := len(e.buf)
+		if err := e.value(t.Value); err != nil {
+			return err
+		}
+		e.buf[start] |= flagShared
+		return nil
+	case Ref:
+		return e.value(t.Target)
+	}
+
+	return e.object(v)
+}
+
+func (e *encoder) object(v Value) error {
+	switch t := v.(type) {
+	case nil:
+		return fmt.Errorf("marshal: cannot encode nil value")
+	case None:
+		e.op(opNone)
+	case Bool:
+		if t {
+			e.op(opTrue)
+		} else {
+			e.op(opFalse)
+		}
+	case Int:
+		e.int(t)
+	case Float:
+		if t.op != opReal && math.Float64bits(t.Value) == 0 {
+			e.op(opZeroReal)
+			return nil
+		}
+		e.op(opReal)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(t.Value))
+	case String:
+		e.string(t)
+	case Unicode:
+		e.unicode(t)
+	case Buffer:
+		e.op(opBuffer)
+		e.sized(t)
+	case Global:
+		e.op(opGlobal)
+		e.sized([]byte(t))
+	case TableString:
+		e.op(opStringTable)
+		e.buf = append(e.buf, byte(t))
+	case SubStream:
+		e.op(opSubStream)
+		e.sized(t)
+
+	case *Tuple:
+		n := len(t.Items)
+		switch {
+		case n == 0 && t.op != opTuple:
+			e.op(opEmptyTuple)
+		case n == 1 && t.op != opTuple:
+			e.op(opOneTuple)
+		case n == 2 && t.op != opTuple:
+			e.op(opTwoTuple)
+		default:
+			e.op(opTuple)
+			e.size(n)
+		}
+		return e.values(t.Items)
+	case *List:
+		n := len(t.Items)
+		switch {
+		case n == 0 && t.op != opList:
+			e.op(opEmptyList)
+		case n == 1 && t.op != opList:
+			e.op(opOneList)
+		default:
+			e.op(opList)
+			e.size(n)
+		}
+		return e.values(t.Items)
+	case *Dict:
+		e.op(opDict)
+		e.size(len(t.Entries))
+		for _, entry := range t.Entries {
+			if err := e.value(entry.Value); err != nil {
+				return err
+			}
+			if err := e.value(entry.Key); err != nil {
+				return err
+			}
+		}
+	case *Object:
+		e.op(opObject)
+		if err := e.value(t.Type); err != nil {
+			return err
+		}
+		return e.value(t.Args)
+	case *ObjectEx:
+		if t.Reduce {
+			e.op(opObjectEx2)
+		} else {
+			e.op(opObjectEx1)
+		}
+		if err := e.value(t.Header); err != nil {
+			return err
+		}
+		if err := e.values(t.List); err != nil {
+			return err
+		}
+		e.op(opPackedTerminator)
+		for _, entry := range t.Dict {
+			if err := e.value(entry.Key); err != nil {
+				return err
+			}
+			if err := e.value(entry.Value); err != nil {
+				return err
+			}
+		}
+		e.op(opPackedTerminator)
+	case *SubStruct:
+		e.op(opSubStruct)
+		return e.value(t.Value)
+	case *Checksum:
+		e.op(opChecksumedStream)
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, t.Sum)
+		return e.value(t.Value)
+	default:
+		return fmt.Errorf("marshal: cannot encode %T", v)
+	}
+	return nil
+}
+
+func (e *encoder) int(i Int) {
+	v := i.Value
+	switch {
+	case i.op == opVarInteger && i.size <= 8 && fitsBytes(v, i.size):
+		e.op(opVarInteger)
+		e.size(i.size)
+		for n := 0; n < i.size; n++ {
+			e.buf = append(e.buf, byte(uint64(v)>>(8*n)))
+		}
+	case i.op == opLongLong:
+		e.op(opLongLong)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(v))
+	case i.op == opLong && fitsBytes(v, 4):
+		e.op(opLong)
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v))
+	case i.op == opShort && fitsBytes(v, 2):
+		e.op(opShort)
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v))
+	case i.op == opByte && fitsBytes(v, 1):
+		e.op(opByte)
+		e.buf = append(e.buf, byte(v))
+	case v == -1:
+		e.op(opMinusOne)
+	case v == 0:
+		e.op(opZero)
+	case v == 1:
+		e.op(opOne)
+	case fitsBytes(v, 1):
+		e.op(opByte)
+		e.buf = append(e.buf, byte(v))
+	case fitsBytes(v, 2):
+		e.op(opShort)
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v))
+	case fitsBytes(v, 4):
+		e.op(opLong)
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v))
+	default:
+		e.op(opLongLong)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(v))
+	}
+}
+
+// fitsBytes reports whether v survives truncation to n bytes and sign extension.
+func fitsBytes(v int64, n int) bool {
+	if n >= 8 {
+		return true
+	}
+	if n <= 0 {
+		return v == 0
+	}
+	shift := 64 - 8*uint(n)
+	return v<<shift>>shift == v
+}
+
+func (e *encoder) string(s String) {
+	n := len(s.Value)
+	switch {
+	case s.op == opLongString:
+		e.op(opLongString)
+		e.sized([]byte(s.Value))
+	case s.op == opShortString && n <= 0xFF:
+		e.op(opShortString)
+		e.buf = append(e.buf, byte(n))
+		e.buf = append(e.buf, s.Value...)
+	case n == 0:
+		e.op(opEmptyString)
+	case n == 1:
+		e.op(opCharString)
+		e.buf = append(e.buf, s.Value[0])
+	case n <= 0xFF:
+		e.op(opShortString)
+		e.buf = append(e.buf, byte(n))
+		e.buf = append(e.buf, s.Value...)
+	default:
+		e.op(opLongString)
+		e.sized([]byte(s.Value))
+	}
+}
+
+func (e *encoder) unicode(u Unicode) {
+	switch u.op {
+	case opWStringUCS2, opWStringUCS2Char:
+		units := utf16.Encode([]rune(u.Value))
+		if len(units) == 1 && u.op == opWStringUCS2Char {
+			e.op(opWStringUCS2Char)
+		} else {
+			e.op(opWStringUCS2)
+			e.size(len(units))
+		}
+		for _, unit := range units {
+			e.buf = binary.LittleEndian.AppendUint16(e.buf, unit)
+		}
+		return
+	}
+
+	if u.Value == "" && u.op != opWStringUTF8 {
+		e.op(opEmptyWString)
+		return
+	}
+	e.op(opWStringUTF8)
+	e.sized([]byte(u.Value))
+}
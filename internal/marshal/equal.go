@@ -0,0 +1,136 @@
+package marshal
+
+import "bytes"
+
+// Equal reports whether a and b hold the same data, regardless of how either
+// was encoded. Shared objects and references compare by their contents, and
+// byte strings equal unicode strings with the same text.
+func Equal(a, b Value) bool {
+	return equal(a, b, make(map[[2]*Shared]bool))
+}
+
+func equal(a, b Value, visiting map[[2]*Shared]bool) bool {
+	// Break cycles through shared objects by assuming equality for pairs
+	// already being compared further up the stack
+	sa, aShared := sharedOf(a)
+	sb, bShared := sharedOf(b)
+	if aShared && bShared {
+		pair := [2]*Shared{sa, sb}
+		if visiting[pair] {
+			return true
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+	}
+
+	a, b = Deref(a), Deref(b)
+
+	if ta, ok := textOf(a); ok {
+		tb, ok := textOf(b)
+		return ok && ta == tb
+	}
+
+	switch va := a.(type) {
+	case None:
+		_, ok := b.(None)
+		return ok
+	case Bool:
+		vb, ok := b.(Bool)
+		return ok && va == vb
+	case Int:
+		vb, ok := b.(Int)
+		return ok && va.Value == vb.Value
+	case Float:
+		vb, ok := b.(Float)
+		return ok && va.Value == vb.Value
+	case Buffer:
+		vb, ok := b.(Buffer)
+		return ok && bytes.Equal(va, vb)
+	case SubStream:
+		vb, ok := b.(SubStream)
+		return ok && bytes.Equal(va, vb)
+	case Global:
+		vb, ok := b.(Global)
+		return ok && va == vb
+	case TableString:
+		vb, ok := b.(TableString)
+		return ok && va == vb
+	case *Tuple:
+		vb, ok := b.(*Tuple)
+		return ok && equalItems(va.Items, vb.Items, visiting)
+	case *List:
+		vb, ok := b.(*List)
+		return ok && equalItems(va.Items, vb.Items, visiting)
+	case *Dict:
+		vb, ok := b.(*Dict)
+		return ok && equalEntries(va.Entries, vb.Entries, visiting)
+	case *Object:
+		vb, ok := b.(*Object)
+		return ok && equal(va.Type, vb.Type, visiting) && equal(va.Args, vb.Args, visiting)
+	case *ObjectEx:
+		vb, ok := b.(*ObjectEx)
+		return ok && va.Reduce == vb.Reduce && equal(va.Header, vb.Header, visiting) &&
+			equalItems(va.List, vb.List, visiting) && equalEntries(va.Dict, vb.Dict, visiting)
+	case *SubStruct:
+		vb, ok := b.(*SubStruct)
+		return ok && equal(va.Value, vb.Value, visiting)
+	case *Checksum:
+		vb, ok := b.(*Checksum)
+		return ok && va.Sum == vb.Sum && equal(va.Value, vb.Value, visiting)
+	}
+
+	return false
+}
+
+func sharedOf(v Value) (*Shared, bool) {
+	switch t := v.(type) {
+	case *Shared:
+		return t, true
+	case Ref:
+		return t.Target, t.Target != nil
+	}
+	return nil, false
+}
+
+// textOf returns the contents of a String or Unicode.
+func textOf(v Value) (string, bool) {
+	switch t := v.(type) {
+	case String:
+		return t.Value, true
+	case Unicode:
+		return t.Value, true
+	}
+	return "", false
+}
+
+func equalItems(a, b []Value, visiting map[[2]*Shared]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(a[i], b[i], visiting) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalEntries compares dicts as mappings, ignoring entry order.
+func equalEntries(a, b []Entry, visiting map[[2]*Shared]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, ea := range a {
+		found := false
+		for _, eb := range b {
+			if equal(ea.Key, eb.Key, visiting) {
+				found = equal(ea.Value, eb.Value, visiting)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+// Package marshal decodes and encodes the serialization format used by the
+// Eve Online client for its settings files (core_char_*.dat, core_user_*.dat).
+//
+// A stream starts with the header byte 0x7E and a little-endian uint32 giving
+// the number of shared objects, followed by a single encoded object and a
+// trailing table of one little-endian int32 slot per shared object.
+//
+// Decoded values remember which of the equivalent encodings they were read
+// with, so an unmodified tree re-encodes to the original bytes. Values built
+// or changed in Go fall back to the client's smallest encoding.
+package marshal
+
+// Value is a decoded object. It is one of None, Bool, Int, Float, String,
+// Unicode, Buffer, Global, TableString, SubStream, *Tuple, *List, *Dict,
+// *Object, *ObjectEx, *SubStruct, *Checksum, *Shared or Ref.
+type Value interface {
+	isValue()
+}
+
+// None is the Python None object.
+type None struct{}
+
+// Bool is a boolean.
+type Bool bool
+
+// Int is an integer of up to 64 bits.
+type Int struct {
+	Value int64
+	op    byte
+	size  int // byte length of a variable-length integer
+}
+
+// Float is a double precision float.
+type Float struct {
+	Value float64
+	op    byte
+}
+
+// String is a byte string.
+type String struct {
+	Value string
+	op    byte
+}
+
+// Unicode is a unicode string.
+type Unicode struct {
+	Value string
+	op    byte
+}
+
+// Buffer is a raw byte buffer.
+type Buffer []byte
+
+// Global is a reference to a global name such as a class, e.g. "blue.DBRow".
+type Global string
+
+// TableString is a reference to an entry of the client's built-in string
+// table. The table itself is not part of the stream, so only the index is kept.
+type TableString uint8
+
+// SubStream is a nested marshal stream kept as its raw encoded bytes.
+type SubStream []byte
+
+// Tuple is an immutable sequence.
+type Tuple struct {
+	Items []Value
+	op    byte
+}
+
+// List is a mutable sequence.
+type List struct {
+	Items []Value
+	op    byte
+}
+
+// Entry is a single key/value pair of a Dict.
+type Entry struct {
+	Key   Value
+	Value Value
+}
+
+// Dict is a mapping. Entries keep the order in which they were encoded.
+type Dict struct {
+	Entries []Entry
+}
+
+// Object is an instance of a class created from its constructor arguments.
+type Object struct {
+	Type Value
+	Args Value
+}
+
+// ObjectEx is an instance restored through the pickle reduce protocol.
+// Reduce distinguishes the two extended object opcodes.
+type ObjectEx struct {
+	Header Value
+	List   []Value
+	Dict   []Entry
+	Reduce bool
+}
+
+// SubStruct wraps a single nested object.
+type SubStruct struct {
+	Value Value
+}
+
+// Checksum wraps an object prefixed by a checksum of its encoding.
+type Checksum struct {
+	Sum   uint32
+	Value Value
+}
+
+// Shared is an object stored in the stream's shared object table so that it
+// can be referenced again later with a Ref.
+type Shared struct {
+	Value Value
+	slot  int32
+}
+
+// Ref is a reference to a previously encoded shared object.
+type Ref struct {
+	Target *Shared
+}
+
+func (None) isValue()        {}
+func (Bool) isValue()        {}
+func (Int) isValue()         {}
+func (Float) isValue()       {}
+func (String) isValue()      {}
+func (Unicode) isValue()     {}
+func (Buffer) isValue()      {}
+func (Global) isValue()      {}
+func (TableString) isValue() {}
+func (SubStream) isValue()   {}
+func (*Tuple) isValue()      {}
+func (*List) isValue()       {}
+func (*Dict) isValue()       {}
+func (*Object) isValue()     {}
+func (*ObjectEx) isValue()   {}
+func (*SubStruct) isValue()  {}
+func (*Checksum) isValue()   {}
+func (*Shared) isValue()     {}
+func (Ref) isValue()         {}
+
+// Get returns the value stored under a string or unicode key equal to key.
+func (d *Dict) Get(key string) (Value, bool) {
+	for _, e := range d.Entries {
+		if k, ok := Text(e.Key); ok && k == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set replaces the value stored under key, or appends a new byte string key.
+func (d *Dict) Set(key string, value Value) {
+	for i, e := range d.Entries {
+		if k, ok := Text(e.Key); ok && k == key {
+			d.Entries[i].Value = value
+			return
+		}
+	}
+	d.Entries = append(d.Entries, Entry{Key: String{Value: key}, Value: value})
+}
+
+// Delete removes the entry stored under key. It reports whether one existed.
+func (d *Dict) Delete(key string) bool {
+	for i, e := range d.Entries {
+		if k, ok := Text(e.Key); ok && k == key {
+			d.Entries = append(d.Entries[:i], d.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Deref returns the object behind a Shared or Ref, or v itself.
+func Deref(v Value) Value {
+	for {
+		switch t := v.(type) {
+		case *Shared:
+			v = t.Value
+		case Ref:
+			if t.Target == nil {
+				return nil
+			}
+			v = t.Target.Value
+		default:
+			return v
+		}
+	}
+}
+
+// Text returns the contents of a String, Unicode or Global.
+func Text(v Value) (string, bool) {
+	switch t := Deref(v).(type) {
+	case String:
+		return t.Value, true
+	case Unicode:
+		return t.Value, true
+	case Global:
+		return string(t), true
+	}
+	return "", false
+}
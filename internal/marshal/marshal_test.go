@@ -0,0 +1,260 @@
+package marshal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoundTripFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.dat"))
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			v, err := Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+
+			encoded, err := Marshal(v)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if !bytes.Equal(encoded, data) {
+				t.Errorf("round trip mismatch:\n got  %x\n want %x", encoded, data)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSettings(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "char_settings.dat"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	v, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	root, ok := v.(*Dict)
+	if !ok {
+		t.Fatalf("expected *Dict root, got %T", v)
+	}
+
+	overview, ok := root.Get("overview")
+	if !ok {
+		t.Fatal("missing overview section")
+	}
+	tabs, ok := overview.(*Dict).Get("tabsettings")
+	if !ok {
+		t.Fatal("missing tabsettings entry")
+	}
+
+	// Entries are (timestamp, value) tuples
+	entry := tabs.(*Tuple)
+	if ts := entry.Items[0].(Int).Value; ts != 133476000000000000 {
+		t.Errorf("timestamp = %d, want 133476000000000000", ts)
+	}
+
+	tab := entry.Items[1].(*Dict).Entries[1]
+	if key := tab.Key.(Int).Value; key != 1 {
+		t.Errorf("tab key = %d, want 1", key)
+	}
+	name, _ := tab.Value.(*Dict).Get("name")
+	if text, _ := Text(name); text != "PvP — Fleet" {
+		t.Errorf("tab name = %q, want %q", text, "PvP — Fleet")
+	}
+
+	general, _ := entry.Items[1].(*Dict).Entries[0].Value.(*Dict).Get("name")
+	if u, ok := general.(Unicode); !ok || u.Value != "General" {
+		t.Errorf("expected UCS-2 unicode 'General', got %#v", general)
+	}
+}
+
+func TestUnmarshalSharedReferences(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "shared_refs.dat"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	v, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	root := v.(*Dict)
+
+	a, _ := root.Get("a")
+	c, _ := root.Get("c")
+	b, _ := root.Get("b")
+
+	if text, _ := Text(a); text != "shared string" {
+		t.Errorf("a = %q, want 'shared string'", text)
+	}
+
+	ref, ok := c.(Ref)
+	if !ok || ref.Target != b.(*Shared) {
+		t.Fatalf("c should reference b, got %#v", c)
+	}
+
+	nested := Deref(b).(*Tuple).Items[1]
+	if text, _ := Text(nested); text != "shared string" {
+		t.Errorf("nested reference = %q, want 'shared string'", text)
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		value Value
+		want  []byte
+	}{
+		{"zero", Int{Value: 0}, []byte{opZero}},
+		{"minus one", Int{Value: -1}, []byte{opMinusOne}},
+		{"byte", Int{Value: -5}, []byte{opByte, 0xFB}},
+		{"short", Int{Value: 1000}, []byte{opShort, 0xE8, 0x03}},
+		{"long", Int{Value: 1 << 20}, []byte{opLong, 0x00, 0x00, 0x10, 0x00}},
+		{"char string", String{Value: "x"}, []byte{opCharString, 'x'}},
+		{"short string", String{Value: "abc"}, []byte{opShortString, 3, 'a', 'b', 'c'}},
+		{"empty unicode", Unicode{}, []byte{opEmptyWString}},
+		{"two tuple", &Tuple{Items: []Value{None{}, Bool(true)}}, []byte{opTwoTuple, opNone, opTrue}},
+		{"empty list", &List{}, []byte{opEmptyList}},
+		{"dict", &Dict{Entries: []Entry{{Key: String{Value: "k"}, Value: Int{Value: 1}}}},
+			[]byte{opDict, 1, opOne, opCharString, 'k'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			want := append([]byte{headerByte, 0, 0, 0, 0}, tt.want...)
+			if !bytes.Equal(got, want) {
+				t.Errorf("Marshal() = %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+func TestMarshalModifiedValueOutgrowsEncoding(t *testing.T) {
+	// A decoded one-byte integer that no longer fits must be widened
+	v, err := Unmarshal([]byte{headerByte, 0, 0, 0, 0, opByte, 5})
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	i := v.(Int)
+	i.Value = 300
+
+	got, err := Marshal(i)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := []byte{headerByte, 0, 0, 0, 0, opShort, 0x2C, 0x01}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = %x, want %x", got, want)
+	}
+}
+
+func TestMarshalTransplantedReference(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "shared_refs.dat"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	v, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	// Move a reference into a tree that lacks its target
+	c, _ := v.(*Dict).Get("c")
+	tree := &List{Items: []Value{c, c}}
+
+	encoded, err := Marshal(tree)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("Unmarshal of re-encoded tree failed: %v", err)
+	}
+
+	items := decoded.(*List).Items
+	first, ok := items[0].(*Shared)
+	if !ok {
+		t.Fatalf("expected first item to be the inlined shared object, got %T", items[0])
+	}
+	if ref, ok := items[1].(Ref); !ok || ref.Target != first {
+		t.Errorf("expected second item to reference the first, got %#v", items[1])
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad header", []byte{0x00, 0, 0, 0, 0, opNone}},
+		{"truncated", []byte{headerByte, 0, 0, 0, 0, opLong, 1, 2}},
+		{"trailing data", []byte{headerByte, 0, 0, 0, 0, opNone, opNone}},
+		{"unknown reference", []byte{headerByte, 0, 0, 0, 0, opSavedElement, 1}},
+		{"unsupported opcode", []byte{headerByte, 0, 0, 0, 0, opPackedRow}},
+		{"missing shared object", []byte{headerByte, 1, 0, 0, 0, opNone, 1, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Unmarshal(tt.data); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	shared := &Shared{Value: String{Value: "x"}}
+
+	tests := []struct {
+		name string
+		a, b Value
+		want bool
+	}{
+		{"different encodings", Int{Value: 5, op: opLong}, Int{Value: 5}, true},
+		{"string and unicode", String{Value: "abc"}, Unicode{Value: "abc"}, true},
+		{"shared and plain", Ref{Target: shared}, String{Value: "x"}, true},
+		{"different ints", Int{Value: 5}, Int{Value: 6}, false},
+		{"int and float", Int{Value: 1}, Float{Value: 1}, false},
+		{"tuple and list", &Tuple{}, &List{}, false},
+		{"dict order", &Dict{Entries: []Entry{
+			{Key: String{Value: "a"}, Value: None{}},
+			{Key: String{Value: "b"}, Value: Bool(true)},
+		}}, &Dict{Entries: []Entry{
+			{Key: String{Value: "b"}, Value: Bool(true)},
+			{Key: String{Value: "a"}, Value: None{}},
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package settings
+
+import (
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// ChangeKind describes how a setting differs between two files.
+type ChangeKind string
+
+const (
+	// Added means the setting only exists in the new file.
+	Added ChangeKind = "added"
+	// Removed means the setting only exists in the old file.
+	Removed ChangeKind = "removed"
+	// Changed means the setting exists in both files with different values.
+	Changed ChangeKind = "changed"
+)
+
+// Change is a single difference between two settings files.
+type Change struct {
+	Section string
+	Path    string // dot-separated path below the section, empty for the whole section
+	Kind    ChangeKind
+	Old     marshal.Value
+	New     marshal.Value
+}
+
+// Diff compares two settings files section by section and returns the changes
+// that turn oldFile into newFile. Dicts are compared key by key, entry
+// timestamps are ignored, and any other differing value is reported whole.
+func Diff(oldFile, newFile *File) ([]Change, error) {
+	oldSections, err := oldFile.Sections()
+	if err != nil {
+		return nil, err
+	}
+	newSections, err := newFile.Sections()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	diffDicts(oldSections, newSections, "", "", &changes)
+	return changes, nil
+}
+
+// diffDicts compares two dicts key by key. At the top level (section == "")
+// each key names a section.
+func diffDicts(oldDict, newDict *marshal.Dict, section, prefix string, changes *[]Change) {
+	// at returns the section and path of key below prefix
+	at := func(key string) (string, string) {
+		if section == "" {
+			return key, ""
+		}
+		return section, joinPath(prefix, key)
+	}
+
+	for _, oe := range oldDict.Entries {
+		key := KeyString(oe.Key)
+		sec, path := at(key)
+		ne, ok := lookup(newDict, key)
+		if !ok {
+			*changes = append(*changes, Change{Section: sec, Path: path, Kind: Removed, Old: oe.Value})
+			continue
+		}
+		diffValues(oe.Value, ne, sec, path, changes)
+	}
+
+	for _, ne := range newDict.Entries {
+		key := KeyString(ne.Key)
+		if _, ok := lookup(oldDict, key); !ok {
+			sec, path := at(key)
+			*changes = append(*changes, Change{Section: sec, Path: path, Kind: Added, New: ne.Value})
+		}
+	}
+}
+
+func diffValues(oldValue, newValue marshal.Value, section, path string, changes *[]Change) {
+	oldInner := marshal.Deref(EntryValue(oldValue))
+	newInner := marshal.Deref(EntryValue(newValue))
+
+	oldDict, oldIsDict := oldInner.(*marshal.Dict)
+	newDict, newIsDict := newInner.(*marshal.Dict)
+	if oldIsDict && newIsDict {
+		diffDicts(oldDict, newDict, section, path, changes)
+		return
+	}
+
+	if !marshal.Equal(oldInner, newInner) {
+		*changes = append(*changes, Change{Section: section, Path: path, Kind: Changed, Old: oldValue, New: newValue})
+	}
+}
+
+// lookup returns the value stored in d under the key displayed as key.
+func lookup(d *marshal.Dict, key string) (marshal.Value, bool) {
+	for _, e := range d.Entries {
+		if KeyString(e.Key) == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
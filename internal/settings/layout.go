@@ -0,0 +1,174 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// WindowsSection is the settings section holding window positions and sizes.
+const WindowsSection = "windows"
+
+// Resolution is a screen size in pixels.
+type Resolution struct {
+	Width  int
+	Height int
+}
+
+func (r Resolution) String() string {
+	return fmt.Sprintf("%dx%d", r.Width, r.Height)
+}
+
+// ParseResolution parses a resolution written as WIDTHxHEIGHT, e.g. 1920x1080.
+func ParseResolution(s string) (Resolution, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if ok {
+		width, werr := strconv.Atoi(w)
+		height, herr := strconv.Atoi(h)
+		if werr == nil && herr == nil && width > 0 && height > 0 {
+			return Resolution{Width: width, Height: height}, nil
+		}
+	}
+	return Resolution{}, fmt.Errorf("invalid resolution '%s' (expected WIDTHxHEIGHT, e.g. 1920x1080)", s)
+}
+
+// ParseRescale parses a rescale specification written as FROM:TO, e.g.
+// 3840x2160:1920x1080.
+func ParseRescale(s string) (from, to Resolution, err error) {
+	f, t, ok := strings.Cut(s, ":")
+	if !ok {
+		return from, to, fmt.Errorf("invalid rescale '%s' (expected FROM:TO, e.g. 3840x2160:1920x1080)", s)
+	}
+	if from, err = ParseResolution(f); err != nil {
+		return from, to, err
+	}
+	if to, err = ParseResolution(t); err != nil {
+		return from, to, err
+	}
+	return from, to, nil
+}
+
+// windowPositionsPrefix starts the keys of the windows section holding the
+// positions and sizes of windows, keyed by window name.
+const windowPositionsPrefix = "windowSizesAndPositions"
+
+// RescaleWindows rewrites the window rectangles in the windows section of f
+// from one screen resolution to another and returns how many it changed.
+//
+// Window rectangles are the (left, top, width, height) sequences of integers
+// stored in the windowSizesAndPositions_* entries of the section. Each is
+// scaled proportionally, then clamped so the window fits on the target screen.
+func RescaleWindows(f *File, from, to Resolution) (int, error) {
+	section, err := sectionDict(f, WindowsSection, false)
+	if err != nil || section == nil {
+		return 0, err
+	}
+
+	r := rescaler{from: from, to: to, seen: make(map[marshal.Value]bool)}
+	for _, e := range section.Entries {
+		if !strings.HasPrefix(KeyString(e.Key), windowPositionsPrefix) {
+			continue
+		}
+		windows, ok := marshal.Deref(EntryValue(e.Value)).(*marshal.Dict)
+		if !ok {
+			continue
+		}
+		for _, w := range windows.Entries {
+			r.rescaleWindow(w.Value)
+		}
+	}
+	return r.count, nil
+}
+
+// rescaler rewrites window rectangles in place.
+type rescaler struct {
+	from, to Resolution
+	count    int
+	seen     map[marshal.Value]bool // rectangles already rewritten via shared references
+}
+
+// rescaleWindow rewrites v if it is a window rectangle.
+func (r *rescaler) rescaleWindow(v marshal.Value) {
+	var items []marshal.Value
+	switch t := marshal.Deref(v).(type) {
+	case *marshal.Tuple:
+		if r.seen[t] {
+			return
+		}
+		r.seen[t] = true
+		items = t.Items
+	case *marshal.List:
+		if r.seen[t] {
+			return
+		}
+		r.seen[t] = true
+		items = t.Items
+	default:
+		return
+	}
+
+	rect, ok := windowRect(items)
+	if !ok {
+		return
+	}
+	r.rescale(rect)
+	for i, n := range rect {
+		setValue(&items[i], marshal.Int{Value: n})
+	}
+	r.count++
+}
+
+// setValue stores v in *slot, or in the object *slot shares or refers to so
+// the other references to it see the change.
+func setValue(slot *marshal.Value, v marshal.Value) {
+	for {
+		switch t := (*slot).(type) {
+		case *marshal.Shared:
+			slot = &t.Value
+		case marshal.Ref:
+			if t.Target == nil {
+				*slot = v
+				return
+			}
+			slot = &t.Target.Value
+		default:
+			*slot = v
+			return
+		}
+	}
+}
+
+// rescale scales rect from r.from to r.to and clamps it to the target screen.
+func (r *rescaler) rescale(rect []int64) {
+	scale := func(n int64, from, to int) int64 {
+		return (n*int64(to) + int64(from)/2) / int64(from)
+	}
+
+	left := scale(rect[0], r.from.Width, r.to.Width)
+	top := scale(rect[1], r.from.Height, r.to.Height)
+	width := min(max(scale(rect[2], r.from.Width, r.to.Width), 1), int64(r.to.Width))
+	height := min(max(scale(rect[3], r.from.Height, r.to.Height), 1), int64(r.to.Height))
+
+	rect[0] = min(max(left, 0), int64(r.to.Width)-width)
+	rect[1] = min(max(top, 0), int64(r.to.Height)-height)
+	rect[2] = width
+	rect[3] = height
+}
+
+// windowRect returns the values of items if it is a sequence of four integers.
+func windowRect(items []marshal.Value) ([]int64, bool) {
+	if len(items) != 4 {
+		return nil, false
+	}
+	rect := make([]int64, 4)
+	for i, item := range items {
+		n, ok := marshal.Deref(item).(marshal.Int)
+		if !ok {
+			return nil, false
+		}
+		rect[i] = n.Value
+	}
+	return rect, true
+}
@@ -0,0 +1,219 @@
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// ChatSection is the settings section holding chat configuration.
+const ChatSection = "chat"
+
+// chatWindowPrefix starts the window settings keys of chat channels.
+const chatWindowPrefix = "chatchannel_"
+
+// Addition is something a merge added to the target settings.
+type Addition struct {
+	Kind string // e.g. "channel" or "window"
+	Name string
+}
+
+// MergeStrategy merges part of the src settings into dst without removing or
+// overwriting anything dst already has. It returns what was added.
+type MergeStrategy func(src, dst *File) ([]Addition, error)
+
+// mergeStrategies are the available merge strategies by name.
+var mergeStrategies = map[string]MergeStrategy{
+	"chat": MergeChat,
+}
+
+// LookupMergeStrategy returns the merge strategy with the given name.
+func LookupMergeStrategy(name string) (MergeStrategy, error) {
+	strategy, ok := mergeStrategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown merge strategy '%s' (available: %s)", name, strings.Join(MergeStrategyNames(), ", "))
+	}
+	return strategy, nil
+}
+
+// MergeStrategyNames returns the names of the available merge strategies.
+func MergeStrategyNames() []string {
+	names := make([]string, 0, len(mergeStrategies))
+	for name := range mergeStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MergeChat adds the source's chat channels that dst is not a member of, and
+// the window settings of chat channels that dst has none for.
+//
+// Channel membership is the list stored under chat.channels, whose items are
+// (channel, ...) tuples identified by their first element. Chat window
+// settings are the chatchannel_* keys inside the dicts of the windows section.
+func MergeChat(src, dst *File) ([]Addition, error) {
+	var added []Addition
+
+	channels, err := mergeChannels(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range channels {
+		added = append(added, Addition{Kind: "channel", Name: name})
+	}
+
+	windows, err := mergeChatWindows(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range windows {
+		added = append(added, Addition{Kind: "window", Name: name})
+	}
+
+	return added, nil
+}
+
+// mergeChannels appends the source's chat channels missing from dst and
+// returns their names.
+func mergeChannels(src, dst *File) ([]string, error) {
+	srcSection, err := sectionDict(src, ChatSection, false)
+	if err != nil || srcSection == nil {
+		return nil, err
+	}
+	srcEntry, ok := srcSection.Get("channels")
+	if !ok {
+		return nil, nil
+	}
+	srcChannels, ok := marshal.Deref(EntryValue(srcEntry)).(*marshal.List)
+	if !ok {
+		return nil, fmt.Errorf("unexpected chat channel layout in %s", src.Path)
+	}
+
+	dstSection, err := sectionDict(dst, ChatSection, true)
+	if err != nil {
+		return nil, err
+	}
+	dstEntry, ok := dstSection.Get("channels")
+	if !ok {
+		dstEntry = NewEntry(&marshal.List{})
+		dstSection.Set("channels", dstEntry)
+	}
+	dstChannels, ok := marshal.Deref(EntryValue(dstEntry)).(*marshal.List)
+	if !ok {
+		return nil, fmt.Errorf("unexpected chat channel layout in %s", dst.Path)
+	}
+
+	member := make(map[string]bool, len(dstChannels.Items))
+	for _, item := range dstChannels.Items {
+		member[channelID(item)] = true
+	}
+
+	var added []string
+	for _, item := range srcChannels.Items {
+		id := channelID(item)
+		if member[id] {
+			continue
+		}
+		dstChannels.Items = append(dstChannels.Items, item)
+		member[id] = true
+		added = append(added, id)
+	}
+	return added, nil
+}
+
+// channelID identifies a chat channel list item by its first element.
+func channelID(v marshal.Value) string {
+	switch t := marshal.Deref(v).(type) {
+	case *marshal.Tuple:
+		if len(t.Items) > 0 {
+			return KeyString(t.Items[0])
+		}
+	case *marshal.List:
+		if len(t.Items) > 0 {
+			return KeyString(t.Items[0])
+		}
+	}
+	return KeyString(v)
+}
+
+// mergeChatWindows copies the chat window settings dst has no settings for
+// and returns their keys.
+func mergeChatWindows(src, dst *File) ([]string, error) {
+	srcSection, err := sectionDict(src, WindowsSection, false)
+	if err != nil || srcSection == nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, e := range srcSection.Entries {
+		srcWindows, ok := marshal.Deref(EntryValue(e.Value)).(*marshal.Dict)
+		if !ok || !hasChatWindows(srcWindows) {
+			continue
+		}
+
+		dstSection, err := sectionDict(dst, WindowsSection, true)
+		if err != nil {
+			return nil, err
+		}
+		key := KeyString(e.Key)
+		dstEntry, ok := lookup(dstSection, key)
+		if !ok {
+			dstEntry = NewEntry(&marshal.Dict{})
+			dstSection.Entries = append(dstSection.Entries, marshal.Entry{Key: e.Key, Value: dstEntry})
+		}
+		dstWindows, ok := marshal.Deref(EntryValue(dstEntry)).(*marshal.Dict)
+		if !ok {
+			return nil, fmt.Errorf("unexpected window settings layout for %s in %s", key, dst.Path)
+		}
+
+		for _, w := range srcWindows.Entries {
+			name := KeyString(w.Key)
+			if !strings.HasPrefix(name, chatWindowPrefix) {
+				continue
+			}
+			if _, ok := lookup(dstWindows, name); ok {
+				continue
+			}
+			dstWindows.Entries = append(dstWindows.Entries, w)
+			added = append(added, name)
+		}
+	}
+	return added, nil
+}
+
+func hasChatWindows(d *marshal.Dict) bool {
+	for _, e := range d.Entries {
+		if strings.HasPrefix(KeyString(e.Key), chatWindowPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionDict returns the named section of f. A missing section is nil, or
+// a new empty section if create is set.
+func sectionDict(f *File, name string, create bool) (*marshal.Dict, error) {
+	sections, err := f.Sections()
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := sections.Get(name)
+	if !ok {
+		if !create {
+			return nil, nil
+		}
+		section := &marshal.Dict{}
+		sections.Set(name, section)
+		return section, nil
+	}
+
+	section, ok := marshal.Deref(v).(*marshal.Dict)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s section layout in %s", name, f.Path)
+	}
+	return section, nil
+}
@@ -0,0 +1,296 @@
+package settings
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+	"gopkg.in/yaml.v3"
+)
+
+// OverviewSection is the settings section holding the overview configuration.
+const OverviewSection = "overview"
+
+// overviewPackKeys maps the keys of an in-game overview pack to the entries
+// of the overview settings section they are stored in.
+var overviewPackKeys = map[string]string{
+	"presets":          "overviewPresets",
+	"tabSetup":         "tabsettings",
+	"overviewColumns":  "overviewColumns",
+	"columnOrder":      "overviewColumnOrder",
+	"flagOrder":        "flagOrder",
+	"flagStates":       "flagStates",
+	"backgroundOrder":  "backgroundOrder",
+	"backgroundStates": "backgroundStates",
+	"stateBlinks":      "stateBlinks",
+	"shipLabels":       "shipLabels",
+	"shipLabelOrder":   "shipLabelOrder",
+}
+
+// OverviewPack is an overview profile in the YAML format used by the client
+// to share overviews. Dicts are written as lists of [key, value] pairs.
+type OverviewPack map[string]any
+
+// LoadOverviewPack reads an overview pack from a YAML file.
+func LoadOverviewPack(path string) (OverviewPack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overview pack: %w", err)
+	}
+
+	var pack OverviewPack
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("failed to parse overview pack %s: %w", path, err)
+	}
+	if pack == nil {
+		return nil, fmt.Errorf("overview pack %s is empty", path)
+	}
+	return pack, nil
+}
+
+// Save writes the overview pack to a YAML file.
+func (p OverviewPack) Save(path string) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create overview pack: %w", err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write overview pack: %w", cerr)
+		}
+	}()
+
+	return p.Encode(file)
+}
+
+// Encode writes the overview pack as YAML to w.
+func (p OverviewPack) Encode(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]any(p)); err != nil {
+		return fmt.Errorf("failed to encode overview pack: %w", err)
+	}
+	return enc.Close()
+}
+
+// ExportOverview builds an overview pack from the overview section of f.
+func ExportOverview(f *File) (OverviewPack, error) {
+	overview, err := overviewSection(f, false)
+	if err != nil {
+		return nil, err
+	}
+
+	pack := OverviewPack{}
+	for packKey, settingsKey := range overviewPackKeys {
+		if v, ok := overview.Get(settingsKey); ok {
+			pack[packKey] = toPack(EntryValue(v))
+		}
+	}
+
+	if len(pack) == 0 {
+		return nil, fmt.Errorf("no overview settings found in %s", f.Path)
+	}
+	return pack, nil
+}
+
+// ImportOverview stores the contents of pack in the overview section of f.
+// It returns the pack keys that were imported and those it did not recognise.
+func ImportOverview(f *File, pack OverviewPack) (imported, skipped []string, err error) {
+	overview, err := overviewSection(f, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(pack))
+	for key := range pack {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, packKey := range keys {
+		settingsKey, ok := overviewPackKeys[packKey]
+		if !ok {
+			skipped = append(skipped, packKey)
+			continue
+		}
+		like, _ := overview.Get(settingsKey)
+		overview.Set(settingsKey, NewEntry(fromPack(pack[packKey], EntryValue(like))))
+		imported = append(imported, packKey)
+	}
+
+	return imported, skipped, nil
+}
+
+// overviewSection returns the overview section of f, adding an empty one
+// when create is set and the file has none.
+func overviewSection(f *File, create bool) (*marshal.Dict, error) {
+	overview, err := sectionDict(f, OverviewSection, create)
+	if err == nil && overview == nil {
+		err = fmt.Errorf("no overview section found in %s", f.Path)
+	}
+	return overview, err
+}
+
+// toPack converts a settings value to the pack representation.
+func toPack(v marshal.Value) any {
+	switch t := marshal.Deref(v).(type) {
+	case *marshal.Dict:
+		pairs := make([]any, len(t.Entries))
+		for i, e := range t.Entries {
+			pairs[i] = []any{toPack(e.Key), toPack(e.Value)}
+		}
+		return pairs
+	case *marshal.Tuple:
+		return toPackItems(t.Items)
+	case *marshal.List:
+		return toPackItems(t.Items)
+	case marshal.Float:
+		// YAML has NaN and infinities, unlike the JSON Plain is made for
+		return t.Value
+	}
+	return Plain(v)
+}
+
+func toPackItems(items []marshal.Value) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = toPack(item)
+	}
+	return out
+}
+
+// fromPack converts a pack value back to a settings value shaped like like,
+// the value the setting has in the target (nil if it has none). Packs write
+// tuples and lists alike, and dicts as lists of pairs, so containers take the
+// type of like. Without it, a non-empty list made only of [key, value] pairs
+// with scalar keys becomes a dict, and any other list a list.
+func fromPack(v any, like marshal.Value) marshal.Value {
+	like = marshal.Deref(like)
+
+	switch t := v.(type) {
+	case nil:
+		return marshal.None{}
+	case bool:
+		return marshal.Bool(t)
+	case int:
+		return packNumber(int64(t), like)
+	case int64:
+		return packNumber(t, like)
+	case uint64:
+		return packNumber(int64(t), like)
+	case float64:
+		return marshal.Float{Value: t}
+	case string:
+		if _, ok := like.(marshal.Unicode); ok {
+			return marshal.Unicode{Value: t}
+		}
+		return textValue(t)
+	case []any:
+		return fromPackItems(t, like)
+	}
+	return textValue(fmt.Sprint(v))
+}
+
+// packNumber returns an integer of a pack as a float if like is one.
+func packNumber(i int64, like marshal.Value) marshal.Value {
+	if _, ok := like.(marshal.Float); ok {
+		return marshal.Float{Value: float64(i)}
+	}
+	return marshal.Int{Value: i}
+}
+
+// fromPackItems converts a pack list to a tuple, list or dict, as described
+// in fromPack.
+func fromPackItems(items []any, like marshal.Value) marshal.Value {
+	switch l := like.(type) {
+	case *marshal.Tuple:
+		return &marshal.Tuple{Items: fromPackValues(items, l.Items)}
+	case *marshal.List:
+		return &marshal.List{Items: fromPackValues(items, l.Items)}
+	case *marshal.Dict:
+		if len(items) == 0 || isPairList(items) {
+			return fromPackDict(items, l)
+		}
+	default:
+		if isPairList(items) {
+			return fromPackDict(items, nil)
+		}
+	}
+	return &marshal.List{Items: fromPackValues(items, nil)}
+}
+
+// fromPackValues converts the items of a pack list, each shaped like the item
+// at the same position of likes, or else like the first one.
+func fromPackValues(items []any, likes []marshal.Value) []marshal.Value {
+	values := make([]marshal.Value, len(items))
+	for i, item := range items {
+		var like marshal.Value
+		if i < len(likes) {
+			like = likes[i]
+		} else if len(likes) > 0 {
+			like = likes[0]
+		}
+		values[i] = fromPack(item, like)
+	}
+	return values
+}
+
+// fromPackDict converts a list of [key, value] pairs to a dict. Values are
+// shaped like the value of the same key in like, or else like its first one.
+func fromPackDict(items []any, like *marshal.Dict) *marshal.Dict {
+	var keyLike, valueLike marshal.Value
+	if like != nil && len(like.Entries) > 0 {
+		keyLike, valueLike = like.Entries[0].Key, like.Entries[0].Value
+	}
+
+	dict := &marshal.Dict{}
+	for _, item := range items {
+		pair := item.([]any)
+		key := fromPack(pair[0], keyLike)
+
+		entryLike := valueLike
+		if like != nil {
+			for _, e := range like.Entries {
+				if marshal.Equal(e.Key, key) {
+					entryLike = e.Value
+					break
+				}
+			}
+		}
+		dict.Entries = append(dict.Entries, marshal.Entry{Key: key, Value: fromPack(pair[1], entryLike)})
+	}
+	return dict
+}
+
+// isPairList reports whether items is a non-empty list of [key, value] pairs
+// whose keys are strings or integers.
+func isPairList(items []any) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		pair, ok := item.([]any)
+		if !ok || len(pair) != 2 {
+			return false
+		}
+		switch pair[0].(type) {
+		case string, int, int64, uint64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// textValue returns s as a byte string, or as unicode if it is not ASCII.
+func textValue(s string) marshal.Value {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return marshal.Unicode{Value: s}
+		}
+	}
+	return marshal.String{Value: s}
+}
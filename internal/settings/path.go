@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// Select walks a dot-separated path such as "windows.windowSizesAndPositions_1"
+// from v. Segments match dict keys by their KeyString form and sequence items
+// by index. (timestamp, value) entries are stepped through transparently, so
+// segments after an entry address its value.
+func Select(v marshal.Value, path string) (marshal.Value, error) {
+	if path == "" {
+		return v, nil
+	}
+
+	walked := make([]string, 0, strings.Count(path, ".")+1)
+	for _, segment := range strings.Split(path, ".") {
+		next, ok := child(EntryValue(v), segment)
+		if !ok {
+			if len(walked) == 0 {
+				return nil, fmt.Errorf("path %q: no key %q at top level", path, segment)
+			}
+			return nil, fmt.Errorf("path %q: no key %q under %q", path, segment, strings.Join(walked, "."))
+		}
+		v = next
+		walked = append(walked, segment)
+	}
+
+	return v, nil
+}
+
+// child returns the item of a dict or sequence addressed by segment.
+func child(v marshal.Value, segment string) (marshal.Value, bool) {
+	var items []marshal.Value
+	switch t := marshal.Deref(v).(type) {
+	case *marshal.Dict:
+		for _, e := range t.Entries {
+			if KeyString(e.Key) == segment {
+				return e.Value, true
+			}
+		}
+		return nil, false
+	case *marshal.Tuple:
+		items = t.Items
+	case *marshal.List:
+		items = t.Items
+	default:
+		return nil, false
+	}
+
+	i, err := strconv.Atoi(segment)
+	if err != nil || i < 0 || i >= len(items) {
+		return nil, false
+	}
+	return items[i], true
+}
+
+// KeyString formats a dict key for display and path matching.
+// Strings are used as-is, other scalars in their usual text form and
+// tuples as a parenthesised, comma-separated list.
+func KeyString(v marshal.Value) string {
+	if text, ok := marshal.Text(v); ok {
+		return text
+	}
+
+	switch t := marshal.Deref(v).(type) {
+	case marshal.Int:
+		return strconv.FormatInt(t.Value, 10)
+	case marshal.Float:
+		return strconv.FormatFloat(t.Value, 'g', -1, 64)
+	case marshal.Bool:
+		if t {
+			return "True"
+		}
+		return "False"
+	case marshal.None:
+		return "None"
+	case *marshal.Tuple:
+		parts := make([]string, len(t.Items))
+		for i, item := range t.Items {
+			parts[i] = KeyString(item)
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	}
+
+	return fmt.Sprintf("<%T>", marshal.Deref(v))
+}
@@ -0,0 +1,149 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is a mapping that keeps its keys in their original order when
+// encoded as JSON or YAML.
+type OrderedMap []MapItem
+
+// MapItem is a single key/value pair of an OrderedMap.
+type MapItem struct {
+	Key   string
+	Value any
+}
+
+// MarshalJSON encodes the map as a JSON object in key order.
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, item := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(item.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML encodes the map as a YAML mapping in key order.
+func (m OrderedMap) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, item := range m {
+		var value yaml.Node
+		if err := value.Encode(item.Value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item.Key},
+			&value)
+	}
+	return node, nil
+}
+
+// Plain converts a settings tree into plain Go values that encode naturally
+// as JSON or YAML. Dicts become OrderedMaps keyed by KeyString, tuples and
+// lists become slices. Types without a JSON equivalent become single-key maps
+// such as {"$global": "blue.DBRow"}, as do NaN and infinite floats, e.g.
+// {"$float": "+Inf"}. A shared object reached again while it is being
+// converted becomes {"$ref": "cycle"}.
+func Plain(v marshal.Value) any {
+	return plain(v, make(map[*marshal.Shared]bool))
+}
+
+func plain(v marshal.Value, active map[*marshal.Shared]bool) any {
+	switch t := v.(type) {
+	case *marshal.Shared:
+		if active[t] {
+			return OrderedMap{{Key: "$ref", Value: "cycle"}}
+		}
+		active[t] = true
+		defer delete(active, t)
+		return plain(t.Value, active)
+	case marshal.Ref:
+		return plain(t.Target, active)
+
+	case marshal.None:
+		return nil
+	case marshal.Bool:
+		return bool(t)
+	case marshal.Int:
+		return t.Value
+	case marshal.Float:
+		if math.IsNaN(t.Value) || math.IsInf(t.Value, 0) {
+			return OrderedMap{{Key: "$float", Value: strconv.FormatFloat(t.Value, 'g', -1, 64)}}
+		}
+		return t.Value
+	case marshal.String:
+		return t.Value
+	case marshal.Unicode:
+		return t.Value
+	case marshal.Buffer:
+		return OrderedMap{{Key: "$buffer", Value: hex.EncodeToString(t)}}
+	case marshal.SubStream:
+		return OrderedMap{{Key: "$substream", Value: hex.EncodeToString(t)}}
+	case marshal.Global:
+		return OrderedMap{{Key: "$global", Value: string(t)}}
+	case marshal.TableString:
+		return OrderedMap{{Key: "$stringtable", Value: int(t)}}
+
+	case *marshal.Tuple:
+		return plainItems(t.Items, active)
+	case *marshal.List:
+		return plainItems(t.Items, active)
+	case *marshal.Dict:
+		return plainEntries(t.Entries, active)
+	case *marshal.Object:
+		return OrderedMap{
+			{Key: "$object", Value: plain(t.Type, active)},
+			{Key: "args", Value: plain(t.Args, active)},
+		}
+	case *marshal.ObjectEx:
+		return OrderedMap{
+			{Key: "$objectex", Value: plain(t.Header, active)},
+			{Key: "list", Value: plainItems(t.List, active)},
+			{Key: "dict", Value: plainEntries(t.Dict, active)},
+		}
+	case *marshal.SubStruct:
+		return plain(t.Value, active)
+	case *marshal.Checksum:
+		return plain(t.Value, active)
+	}
+
+	return nil
+}
+
+func plainItems(items []marshal.Value, active map[*marshal.Shared]bool) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = plain(item, active)
+	}
+	return out
+}
+
+func plainEntries(entries []marshal.Entry, active map[*marshal.Shared]bool) OrderedMap {
+	out := make(OrderedMap, len(entries))
+	for i, e := range entries {
+		out[i] = MapItem{Key: KeyString(e.Key), Value: plain(e.Value, active)}
+	}
+	return out
+}
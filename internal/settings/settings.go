@@ -0,0 +1,81 @@
+// Package settings works with the decoded contents of Eve settings files.
+//
+// The client stores a settings file as a dict of named sections such as
+// "windows" or "overview". Each section is a dict mapping setting keys to
+// (timestamp, value) entries.
+package settings
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// File is a decoded settings file.
+type File struct {
+	Path string
+	Root marshal.Value
+}
+
+// Load reads and decodes the settings file at path.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	root, err := marshal.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return &File{Path: path, Root: root}, nil
+}
+
+// Save encodes the settings tree and writes it to path.
+func (f *File) Save(path string) error {
+	data, err := marshal.Marshal(f.Root)
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	return nil
+}
+
+// Sections returns the top-level dict of named sections.
+func (f *File) Sections() (*marshal.Dict, error) {
+	sections, ok := marshal.Deref(f.Root).(*marshal.Dict)
+	if !ok {
+		return nil, fmt.Errorf("unexpected settings layout in %s: top level is not a dict", f.Path)
+	}
+	return sections, nil
+}
+
+// EntryValue returns the value of a (timestamp, value) settings entry,
+// or v itself if it is not shaped like one.
+func EntryValue(v marshal.Value) marshal.Value {
+	t, ok := marshal.Deref(v).(*marshal.Tuple)
+	if !ok || len(t.Items) != 2 {
+		return v
+	}
+	if _, ok := marshal.Deref(t.Items[0]).(marshal.Int); !ok {
+		return v
+	}
+	return t.Items[1]
+}
+
+// filetimeEpochOffset is the number of 100ns intervals between 1601-01-01
+// (the FILETIME epoch) and the Unix epoch.
+const filetimeEpochOffset = 116444736000000000
+
+// NewEntry wraps v in a (timestamp, value) settings entry stamped with the
+// current time.
+func NewEntry(v marshal.Value) *marshal.Tuple {
+	stamp := time.Now().UnixNano()/100 + filetimeEpochOffset
+	return &marshal.Tuple{Items: []marshal.Value{marshal.Int{Value: stamp}, v}}
+}
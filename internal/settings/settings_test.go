@@ -0,0 +1,657 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+	"gopkg.in/yaml.v3"
+)
+
+// str builds a byte string value.
+func str(s string) marshal.String {
+	return marshal.String{Value: s}
+}
+
+// num builds an integer value.
+func num(i int64) marshal.Int {
+	return marshal.Int{Value: i}
+}
+
+// entry builds a (timestamp, value) settings entry.
+func entry(v marshal.Value) *marshal.Tuple {
+	return &marshal.Tuple{Items: []marshal.Value{num(133476000000000000), v}}
+}
+
+// dict builds a dict from alternating string keys and values.
+func dict(kv ...any) *marshal.Dict {
+	d := &marshal.Dict{}
+	for i := 0; i < len(kv); i += 2 {
+		d.Entries = append(d.Entries, marshal.Entry{Key: str(kv[i].(string)), Value: kv[i+1].(marshal.Value)})
+	}
+	return d
+}
+
+func testTree() *marshal.Dict {
+	return dict(
+		"windows", dict(
+			"windowSizesAndPositions_1", entry(dict(
+				"overview", &marshal.Tuple{Items: []marshal.Value{num(10), num(20), num(300), num(400)}},
+			)),
+		),
+		"overview", dict(
+			"overviewColumns", entry(&marshal.List{Items: []marshal.Value{str("ICON"), str("NAME")}}),
+		),
+	)
+}
+
+func TestSelect(t *testing.T) {
+	root := testTree()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"overview.overviewColumns.1", "NAME"},
+		{"overview.overviewColumns.0", "ICON"},
+		{"windows.windowSizesAndPositions_1.overview.2", "300"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			v, err := Select(root, tt.path)
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if got := KeyString(v); got != tt.want {
+				t.Errorf("Select() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Select(root, "overview.missing"); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	tests := []struct {
+		value marshal.Value
+		want  string
+	}{
+		{str("name"), "name"},
+		{marshal.Unicode{Value: "ünï"}, "ünï"},
+		{num(-3), "-3"},
+		{marshal.Bool(true), "True"},
+		{&marshal.Tuple{Items: []marshal.Value{num(1), str("a")}}, "(1, a)"},
+	}
+
+	for _, tt := range tests {
+		if got := KeyString(tt.value); got != tt.want {
+			t.Errorf("KeyString(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPlainJSONKeepsOrder(t *testing.T) {
+	tree := dict("zeta", num(1), "alpha", marshal.Global("blue.DBRow"), "mid", marshal.None{})
+
+	got, err := json.Marshal(Plain(tree))
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+
+	want := `{"zeta":1,"alpha":{"$global":"blue.DBRow"},"mid":null}`
+	if string(got) != want {
+		t.Errorf("JSON = %s, want %s", got, want)
+	}
+}
+
+func TestPlainJSONNonFiniteFloats(t *testing.T) {
+	tree := &marshal.List{Items: []marshal.Value{
+		marshal.Float{Value: math.NaN()},
+		marshal.Float{Value: math.Inf(1)},
+		marshal.Float{Value: math.Inf(-1)},
+		marshal.Float{Value: 0.5},
+	}}
+
+	got, err := json.Marshal(Plain(tree))
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+
+	want := `[{"$float":"NaN"},{"$float":"+Inf"},{"$float":"-Inf"},0.5]`
+	if string(got) != want {
+		t.Errorf("JSON = %s, want %s", got, want)
+	}
+}
+
+func TestPlainYAML(t *testing.T) {
+	got, err := yaml.Marshal(Plain(dict("b", num(2), "a", &marshal.List{Items: []marshal.Value{str("x")}})))
+	if err != nil {
+		t.Fatalf("yaml.Marshal failed: %v", err)
+	}
+
+	want := "b: 2\na:\n    - x\n"
+	if string(got) != want {
+		t.Errorf("YAML = %q, want %q", got, want)
+	}
+}
+
+func TestPlainCycle(t *testing.T) {
+	shared := &marshal.Shared{}
+	list := &marshal.List{Items: []marshal.Value{num(1), marshal.Ref{Target: shared}}}
+	shared.Value = list
+
+	got, err := json.Marshal(Plain(shared))
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	if want := `[1,{"$ref":"cycle"}]`; string(got) != want {
+		t.Errorf("JSON = %s, want %s", got, want)
+	}
+}
+
+func TestLoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "core_char_1.dat")
+	f := &File{Path: path, Root: testTree()}
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	sections, err := loaded.Sections()
+	if err != nil {
+		t.Fatalf("Sections failed: %v", err)
+	}
+	if len(sections.Entries) != 2 {
+		t.Errorf("expected 2 sections, got %d", len(sections.Entries))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldFile := &File{Root: testTree()}
+
+	newTree := testTree()
+	overview, _ := newTree.Get("overview")
+	overview.(*marshal.Dict).Set("tabsettings", entry(dict("0", str("General"))))
+	overview.(*marshal.Dict).Set("overviewColumns", entry(&marshal.List{Items: []marshal.Value{str("ICON")}}))
+	newTree.Delete("windows")
+	newTree.Set("chat", dict())
+	newFile := &File{Root: newTree}
+
+	changes, err := Diff(oldFile, newFile)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	want := []struct {
+		section string
+		path    string
+		kind    ChangeKind
+	}{
+		{"windows", "", Removed},
+		{"overview", "overviewColumns", Changed},
+		{"overview", "tabsettings", Added},
+		{"chat", "", Added},
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(want), len(changes), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Section != w.section || c.Path != w.path || c.Kind != w.kind {
+			t.Errorf("change %d = %s/%s %s, want %s/%s %s", i, c.Section, c.Path, c.Kind, w.section, w.path, w.kind)
+		}
+	}
+}
+
+func TestDiffIgnoresTimestamps(t *testing.T) {
+	newTree := testTree()
+	overview, _ := newTree.Get("overview")
+	columns, _ := overview.(*marshal.Dict).Get("overviewColumns")
+	columns.(*marshal.Tuple).Items[0] = num(1)
+
+	changes, err := Diff(&File{Root: testTree()}, &File{Root: newTree})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+// sectionNames returns the section names of a file in order.
+func sectionNames(t *testing.T, f *File) []string {
+	t.Helper()
+	sections, err := f.Sections()
+	if err != nil {
+		t.Fatalf("Sections failed: %v", err)
+	}
+	names := make([]string, len(sections.Entries))
+	for i, e := range sections.Entries {
+		names[i] = KeyString(e.Key)
+	}
+	return names
+}
+
+func TestTransplantOnly(t *testing.T) {
+	src := &File{Root: dict("overview", dict("a", num(1)), "shortcuts", dict("b", num(2)), "chat", dict("c", num(3)))}
+	dst := &File{Root: dict("chat", dict("mine", num(9)), "overview", dict("old", num(0)), "windows", dict())}
+
+	copied, err := Transplant(src, dst, []string{"overview", "shortcuts"}, nil)
+	if err != nil {
+		t.Fatalf("Transplant failed: %v", err)
+	}
+
+	if got := strings.Join(copied, ","); got != "overview,shortcuts" {
+		t.Errorf("copied = %s, want overview,shortcuts", got)
+	}
+	if got := strings.Join(sectionNames(t, dst), ","); got != "chat,overview,windows,shortcuts" {
+		t.Errorf("sections = %s, want chat,overview,windows,shortcuts", got)
+	}
+
+	overview, _ := Select(dst.Root, "overview.a")
+	if overview == nil {
+		t.Error("overview should come from the source")
+	}
+	if _, err := Select(dst.Root, "chat.mine"); err != nil {
+		t.Error("chat should be kept from the target")
+	}
+
+	if _, err := Transplant(src, dst, []string{"missing"}, nil); err == nil {
+		t.Error("expected error for section missing from source")
+	}
+}
+
+func TestTransplantExclude(t *testing.T) {
+	src := &File{Root: dict("overview", dict("a", num(1)), "chat", dict("c", num(3)), "windows", dict())}
+	dst := &File{Root: dict("chat", dict("mine", num(9)), "extra", dict())}
+
+	copied, err := Transplant(src, dst, nil, []string{"chat", "windows"})
+	if err != nil {
+		t.Fatalf("Transplant failed: %v", err)
+	}
+
+	if got := strings.Join(copied, ","); got != "overview" {
+		t.Errorf("copied = %s, want overview", got)
+	}
+	if got := strings.Join(sectionNames(t, dst), ","); got != "overview,chat" {
+		t.Errorf("sections = %s, want overview,chat", got)
+	}
+	if _, err := Select(dst.Root, "chat.mine"); err != nil {
+		t.Error("chat should be kept from the target")
+	}
+
+	// The result must still encode
+	if _, err := marshal.Marshal(dst.Root); err != nil {
+		t.Errorf("Marshal of transplanted tree failed: %v", err)
+	}
+}
+
+func TestOverviewRoundTrip(t *testing.T) {
+	tabs := &marshal.Dict{Entries: []marshal.Entry{
+		{Key: num(0), Value: dict("name", marshal.Unicode{Value: "PvP — Fleet"}, "overview", str("pvp"))},
+	}}
+	src := &File{Root: dict(
+		"overview", dict(
+			"tabsettings", entry(tabs),
+			"overviewColumns", entry(&marshal.List{Items: []marshal.Value{str("ICON"), str("NAME")}}),
+			"unrelated", entry(num(1)),
+		),
+	)}
+
+	pack, err := ExportOverview(src)
+	if err != nil {
+		t.Fatalf("ExportOverview failed: %v", err)
+	}
+	if _, ok := pack["unrelated"]; ok {
+		t.Error("unrelated overview entries should not be exported")
+	}
+
+	path := filepath.Join(t.TempDir(), "pack.yaml")
+	if err := pack.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadOverviewPack(path)
+	if err != nil {
+		t.Fatalf("LoadOverviewPack failed: %v", err)
+	}
+	loaded["unknownKey"] = 1
+
+	dst := &File{Root: dict("windows", dict())}
+	imported, skipped, err := ImportOverview(dst, loaded)
+	if err != nil {
+		t.Fatalf("ImportOverview failed: %v", err)
+	}
+	if got := strings.Join(imported, ","); got != "overviewColumns,tabSetup" {
+		t.Errorf("imported = %s, want overviewColumns,tabSetup", got)
+	}
+	if got := strings.Join(skipped, ","); got != "unknownKey" {
+		t.Errorf("skipped = %s, want unknownKey", got)
+	}
+
+	for _, key := range []string{"tabsettings", "overviewColumns"} {
+		want, _ := Select(src.Root, "overview."+key)
+		got, err := Select(dst.Root, "overview."+key)
+		if err != nil {
+			t.Fatalf("missing imported %s: %v", key, err)
+		}
+		if !marshal.Equal(EntryValue(got), EntryValue(want)) {
+			t.Errorf("%s = %#v, want %#v", key, got, want)
+		}
+	}
+
+	if _, err := marshal.Marshal(dst.Root); err != nil {
+		t.Errorf("Marshal of imported tree failed: %v", err)
+	}
+}
+
+func TestOverviewRoundTripKeepsTypes(t *testing.T) {
+	pairs := func(values ...int64) *marshal.List {
+		l := &marshal.List{}
+		for i := 0; i+1 < len(values); i += 2 {
+			l.Items = append(l.Items, &marshal.List{Items: []marshal.Value{num(values[i]), num(values[i+1])}})
+		}
+		return l
+	}
+	overview := func(blinks *marshal.Tuple, states *marshal.List, presets *marshal.Dict) *File {
+		return &File{Root: dict("overview", dict(
+			"stateBlinks", entry(blinks),
+			"flagStates", entry(states),
+			"overviewPresets", entry(presets),
+		))}
+	}
+
+	src := overview(
+		&marshal.Tuple{Items: []marshal.Value{num(11), marshal.Float{Value: 2}}},
+		pairs(1, 10, 2, 20),
+		dict("pvp", dict("groups", &marshal.Tuple{Items: []marshal.Value{num(25), num(26)}}, "name", marshal.Unicode{Value: "PvP"})),
+	)
+	// The target already has the same settings, with other values
+	dst := overview(
+		&marshal.Tuple{Items: []marshal.Value{num(5), marshal.Float{Value: 0.5}}},
+		pairs(3, 30),
+		dict("mining", dict("groups", &marshal.Tuple{Items: []marshal.Value{num(9)}}, "name", marshal.Unicode{Value: "Mining"})),
+	)
+
+	pack, err := ExportOverview(src)
+	if err != nil {
+		t.Fatalf("ExportOverview failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := pack.Encode(&buf); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	var loaded OverviewPack
+	if err := yaml.Unmarshal(buf.Bytes(), &loaded); err != nil {
+		t.Fatalf("decoding the pack failed: %v", err)
+	}
+
+	if _, _, err := ImportOverview(dst, loaded); err != nil {
+		t.Fatalf("ImportOverview failed: %v", err)
+	}
+	for _, key := range []string{"stateBlinks", "flagStates", "overviewPresets"} {
+		want, _ := Select(src.Root, "overview."+key)
+		got, _ := Select(dst.Root, "overview."+key)
+		if !marshal.Equal(EntryValue(got), EntryValue(want)) {
+			t.Errorf("%s = %#v, want %#v", key, EntryValue(got), EntryValue(want))
+		}
+	}
+}
+
+func TestExportOverviewMissing(t *testing.T) {
+	if _, err := ExportOverview(&File{Root: dict("windows", dict())}); err == nil {
+		t.Error("expected error without an overview section")
+	}
+}
+
+func TestChords(t *testing.T) {
+	tests := []struct {
+		keys []int
+		want string
+	}{
+		{[]int{0x12, 0x4D}, "Alt+M"},
+		{[]int{0x11, 0x10, 0x70}, "Ctrl+Shift+F1"},
+		{[]int{0x65}, "Num5"},
+		{[]int{0xFE}, "0xFE"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatChord(tt.keys); got != tt.want {
+				t.Errorf("FormatChord() = %s, want %s", got, tt.want)
+			}
+			keys, err := ParseChord(strings.ToLower(tt.want))
+			if err != nil {
+				t.Fatalf("ParseChord failed: %v", err)
+			}
+			if FormatChord(keys) != tt.want {
+				t.Errorf("ParseChord() = %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+
+	if _, err := ParseChord("Ctrl+Hyper"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestShortcuts(t *testing.T) {
+	chord := func(keys ...int64) *marshal.Tuple {
+		tuple := &marshal.Tuple{}
+		for _, k := range keys {
+			tuple.Items = append(tuple.Items, num(k))
+		}
+		return tuple
+	}
+	f := &File{Root: dict(
+		"shortcuts", dict(
+			"CmdToggleMap", entry(chord(0x12, 0x4D)),
+			"CmdToggleOverview", entry(chord(0x11, 0x4F)),
+			"CmdOpenMarket", entry(chord(0x4D, 0x12)),
+			"CmdUnbound", entry(marshal.None{}),
+			"notAShortcut", entry(str("x")),
+		),
+	)}
+
+	shortcuts, err := Shortcuts(f)
+	if err != nil {
+		t.Fatalf("Shortcuts failed: %v", err)
+	}
+	if len(shortcuts) != 4 || shortcuts[0].Action != "CmdOpenMarket" {
+		t.Fatalf("unexpected shortcuts: %+v", shortcuts)
+	}
+
+	conflicts := ShortcutConflicts(shortcuts)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %+v", conflicts)
+	}
+	if got := strings.Join(conflicts[0].Actions, ","); got != "CmdOpenMarket,CmdToggleMap" {
+		t.Errorf("conflicting actions = %s", got)
+	}
+
+	for _, format := range []string{"toml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			data, err := NewShortcutFile(shortcuts).Encode(format)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "keys."+format)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sf, err := LoadShortcutFile(path)
+			if err != nil {
+				t.Fatalf("LoadShortcutFile failed: %v", err)
+			}
+			if sf.Shortcuts["CmdToggleMap"] != "Alt+M" || sf.Shortcuts["CmdUnbound"] != "" {
+				t.Errorf("unexpected file contents: %v", sf.Shortcuts)
+			}
+		})
+	}
+
+	// Importing replaces listed actions and keeps the rest
+	dst := &File{Root: dict("shortcuts", dict("CmdOther", entry(chord(0x70))))}
+	if err := SetShortcuts(dst, []Shortcut{{Action: "CmdToggleMap", Keys: []int{0x12, 0x4D}}}); err != nil {
+		t.Fatalf("SetShortcuts failed: %v", err)
+	}
+	got, err := Shortcuts(dst)
+	if err != nil {
+		t.Fatalf("Shortcuts failed: %v", err)
+	}
+	if len(got) != 2 || got[1].Action != "CmdToggleMap" || got[1].Chord() != "Alt+M" {
+		t.Errorf("unexpected shortcuts after import: %+v", got)
+	}
+}
+
+func TestParseRescale(t *testing.T) {
+	from, to, err := ParseRescale("3840x2160:1920X1080")
+	if err != nil {
+		t.Fatalf("ParseRescale failed: %v", err)
+	}
+	if from.String() != "3840x2160" || to.String() != "1920x1080" {
+		t.Errorf("ParseRescale() = %s, %s", from, to)
+	}
+
+	for _, bad := range []string{"3840x2160", "3840x2160:0x1080", "big:small", "3840:2160"} {
+		if _, _, err := ParseRescale(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestRescaleWindows(t *testing.T) {
+	rect := func(l, t, w, h int64) *marshal.Tuple {
+		return &marshal.Tuple{Items: []marshal.Value{num(l), num(t), num(w), num(h)}}
+	}
+	shared := &marshal.Shared{Value: rect(400, 400, 800, 600)}
+	sharedLeft := &marshal.Shared{Value: num(1000)}
+	f := &File{Root: dict(
+		"windows", dict(
+			"windowSizesAndPositions_1", entry(dict(
+				"overview", rect(3000, 100, 800, 1800),
+				"offscreen", rect(3800, 2100, 400, 400),
+				"huge", rect(0, 0, 5000, 3000),
+				"a", shared,
+				"b", marshal.Ref{Target: shared},
+				"market", &marshal.Tuple{Items: []marshal.Value{sharedLeft, num(200), num(800), num(600)}},
+			)),
+			"stacked", entry(marshal.Bool(true)),
+			"stackOrder", entry(dict("inventory", rect(1, 2, 3, 4))),
+			"left", entry(marshal.Ref{Target: sharedLeft}),
+		),
+		"overview", dict("rect", entry(rect(3000, 100, 800, 1800))),
+	)}
+
+	n, err := RescaleWindows(f, Resolution{3840, 2160}, Resolution{1920, 1080})
+	if err != nil {
+		t.Fatalf("RescaleWindows failed: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("rescaled %d rectangles, want 5", n)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"windows.windowSizesAndPositions_1.overview", "[1500,50,400,900]"},
+		{"windows.windowSizesAndPositions_1.offscreen", "[1720,880,200,200]"},
+		{"windows.windowSizesAndPositions_1.huge", "[0,0,1920,1080]"},
+		{"windows.windowSizesAndPositions_1.b", "[200,200,400,300]"},
+		{"windows.windowSizesAndPositions_1.market", "[500,100,400,300]"},
+		{"windows.stackOrder.inventory", "[1,2,3,4]"},
+		{"windows.left", "500"},
+		{"overview.rect", "[3000,100,800,1800]"},
+	}
+	for _, tt := range tests {
+		v, err := Select(f.Root, tt.path)
+		if err != nil {
+			t.Fatalf("Select(%s) failed: %v", tt.path, err)
+		}
+		data, _ := json.Marshal(Plain(EntryValue(v)))
+		if string(data) != tt.want {
+			t.Errorf("%s = %s, want %s", tt.path, data, tt.want)
+		}
+	}
+
+	market, _ := Select(f.Root, "windows.windowSizesAndPositions_1.market")
+	if market.(*marshal.Tuple).Items[0] != sharedLeft {
+		t.Error("expected the shared left position to be rewritten in place")
+	}
+}
+
+func TestMergeChat(t *testing.T) {
+	channel := func(id string, n int64) *marshal.Tuple {
+		return &marshal.Tuple{Items: []marshal.Value{str(id), num(n)}}
+	}
+	rect := &marshal.Tuple{Items: []marshal.Value{num(1), num(2), num(3), num(4)}}
+	mine := &marshal.Tuple{Items: []marshal.Value{num(9), num(9), num(9), num(9)}}
+
+	src := &File{Root: dict(
+		"chat", dict("channels", entry(&marshal.List{Items: []marshal.Value{
+			channel("corpid", 98000001), channel("Local", -1), channel("fleet", 5),
+		}})),
+		"windows", dict("windowSizesAndPositions_1", entry(dict(
+			"chatchannel_corp", rect,
+			"chatchannel_fleet", rect,
+			"inventory", rect,
+		))),
+	)}
+	dst := &File{Root: dict(
+		"chat", dict("channels", entry(&marshal.List{Items: []marshal.Value{
+			channel("Local", -1), channel("private", 7),
+		}})),
+		"windows", dict("windowSizesAndPositions_1", entry(dict(
+			"chatchannel_corp", mine,
+		))),
+	)}
+
+	strategy, err := LookupMergeStrategy("chat")
+	if err != nil {
+		t.Fatalf("LookupMergeStrategy failed: %v", err)
+	}
+	added, err := strategy(src, dst)
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	var got []string
+	for _, a := range added {
+		got = append(got, a.Kind+":"+a.Name)
+	}
+	if want := "channel:corpid,channel:fleet,window:chatchannel_fleet"; strings.Join(got, ",") != want {
+		t.Errorf("added = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	channels, _ := Select(dst.Root, "chat.channels")
+	data, _ := json.Marshal(Plain(EntryValue(channels)))
+	if want := `[["Local",-1],["private",7],["corpid",98000001],["fleet",5]]`; string(data) != want {
+		t.Errorf("channels = %s, want %s", data, want)
+	}
+
+	corp, _ := Select(dst.Root, "windows.windowSizesAndPositions_1.chatchannel_corp")
+	if !marshal.Equal(corp, mine) {
+		t.Error("existing chat window settings must be kept")
+	}
+	if _, err := Select(dst.Root, "windows.windowSizesAndPositions_1.inventory"); err == nil {
+		t.Error("non-chat windows must not be merged")
+	}
+
+	if _, err := LookupMergeStrategy("nope"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
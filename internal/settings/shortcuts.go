@@ -0,0 +1,296 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// ShortcutsSection is the settings section holding the key bindings.
+const ShortcutsSection = "shortcuts"
+
+// Shortcut is a key binding: an action and the chord of Windows virtual-key
+// codes bound to it. Keys is empty for an unbound action.
+type Shortcut struct {
+	Action string
+	Keys   []int
+}
+
+// Chord returns the key combination of s in readable form, e.g. "Ctrl+O".
+func (s Shortcut) Chord() string {
+	return FormatChord(s.Keys)
+}
+
+// ShortcutConflict is a chord bound to more than one action.
+type ShortcutConflict struct {
+	Chord   string
+	Actions []string
+}
+
+// ShortcutFile is the human-editable form of a set of key bindings, mapping
+// actions to chords such as "Alt+M". An empty chord unbinds the action.
+type ShortcutFile struct {
+	Shortcuts map[string]string `toml:"shortcuts" json:"shortcuts"`
+}
+
+// virtualKeys names the Windows virtual-key codes used in chords.
+var virtualKeys = map[int]string{
+	0x08: "Backspace", 0x09: "Tab", 0x0D: "Enter", 0x10: "Shift", 0x11: "Ctrl",
+	0x12: "Alt", 0x13: "Pause", 0x14: "CapsLock", 0x1B: "Esc", 0x20: "Space",
+	0x21: "PageUp", 0x22: "PageDown", 0x23: "End", 0x24: "Home", 0x25: "Left",
+	0x26: "Up", 0x27: "Right", 0x28: "Down", 0x2C: "PrintScreen", 0x2D: "Insert",
+	0x2E: "Delete", 0x5B: "Win", 0x6A: "NumMultiply", 0x6B: "NumAdd",
+	0x6D: "NumSubtract", 0x6E: "NumDecimal", 0x6F: "NumDivide",
+	0xBA: ";", 0xBB: "=", 0xBC: ",", 0xBD: "-", 0xBE: ".", 0xBF: "/", 0xC0: "`",
+	0xDB: "[", 0xDC: "\\", 0xDD: "]", 0xDE: "'",
+}
+
+// virtualKeyCodes is the reverse of virtualKeys, keyed by lower-case name.
+var virtualKeyCodes = map[string]int{"control": 0x11, "escape": 0x1B, "return": 0x0D, "del": 0x2E}
+
+func init() {
+	for code := 'A'; code <= 'Z'; code++ {
+		virtualKeys[int(code)] = string(code)
+	}
+	for code := '0'; code <= '9'; code++ {
+		virtualKeys[int(code)] = string(code)
+		virtualKeys[int(code-'0')+0x60] = "Num" + string(code)
+	}
+	for n := 1; n <= 24; n++ {
+		virtualKeys[0x6F+n] = "F" + strconv.Itoa(n)
+	}
+	for code, name := range virtualKeys {
+		virtualKeyCodes[strings.ToLower(name)] = code
+	}
+}
+
+// FormatChord renders virtual-key codes as a chord such as "Ctrl+Shift+F1".
+// Codes without a name are written in hex.
+func FormatChord(keys []int) string {
+	names := make([]string, len(keys))
+	for i, code := range keys {
+		if name, ok := virtualKeys[code]; ok {
+			names[i] = name
+		} else {
+			names[i] = fmt.Sprintf("0x%02X", code)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+// ParseChord parses a chord written by FormatChord. Key names are case
+// insensitive and may also be given as hex codes. An empty chord is unbound.
+func ParseChord(chord string) ([]int, error) {
+	chord = strings.TrimSpace(chord)
+	if chord == "" {
+		return nil, nil
+	}
+
+	var keys []int
+	for _, part := range strings.Split(chord, "+") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if code, ok := virtualKeyCodes[name]; ok {
+			keys = append(keys, code)
+			continue
+		}
+		if strings.HasPrefix(name, "0x") {
+			if code, err := strconv.ParseUint(name[2:], 16, 8); err == nil {
+				keys = append(keys, int(code))
+				continue
+			}
+		}
+		return nil, fmt.Errorf("unknown key '%s' in chord '%s'", strings.TrimSpace(part), chord)
+	}
+	return keys, nil
+}
+
+// Shortcuts returns the key bindings stored in f, sorted by action. Entries
+// that are not a tuple of key codes are skipped.
+func Shortcuts(f *File) ([]Shortcut, error) {
+	section, err := sectionDict(f, ShortcutsSection, false)
+	if err != nil {
+		return nil, err
+	}
+	if section == nil {
+		return nil, fmt.Errorf("no shortcuts section found in %s", f.Path)
+	}
+
+	var shortcuts []Shortcut
+	for _, e := range section.Entries {
+		keys, ok := chordKeys(EntryValue(e.Value))
+		if !ok {
+			continue
+		}
+		shortcuts = append(shortcuts, Shortcut{Action: KeyString(e.Key), Keys: keys})
+	}
+
+	sort.Slice(shortcuts, func(i, j int) bool {
+		return shortcuts[i].Action < shortcuts[j].Action
+	})
+	return shortcuts, nil
+}
+
+// chordKeys returns the key codes of a stored binding. None is unbound.
+func chordKeys(v marshal.Value) ([]int, bool) {
+	var items []marshal.Value
+	switch t := marshal.Deref(v).(type) {
+	case marshal.None:
+		return nil, true
+	case *marshal.Tuple:
+		items = t.Items
+	case *marshal.List:
+		items = t.Items
+	default:
+		return nil, false
+	}
+
+	keys := make([]int, len(items))
+	for i, item := range items {
+		code, ok := marshal.Deref(item).(marshal.Int)
+		if !ok {
+			return nil, false
+		}
+		keys[i] = int(code.Value)
+	}
+	return keys, true
+}
+
+// SetShortcuts stores the given bindings in f, replacing existing bindings of
+// the same actions and leaving all others untouched.
+func SetShortcuts(f *File, shortcuts []Shortcut) error {
+	section, err := sectionDict(f, ShortcutsSection, true)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range shortcuts {
+		var value marshal.Value = marshal.None{}
+		if len(s.Keys) > 0 {
+			chord := &marshal.Tuple{Items: make([]marshal.Value, len(s.Keys))}
+			for i, code := range s.Keys {
+				chord.Items[i] = marshal.Int{Value: int64(code)}
+			}
+			value = chord
+		}
+		section.Set(s.Action, NewEntry(value))
+	}
+	return nil
+}
+
+// ShortcutConflicts returns the chords bound to more than one action. The
+// order of the keys within a chord does not matter.
+func ShortcutConflicts(shortcuts []Shortcut) []ShortcutConflict {
+	byChord := make(map[string][]string)
+	display := make(map[string]string)
+	for _, s := range shortcuts {
+		if len(s.Keys) == 0 {
+			continue
+		}
+		keys := append([]int(nil), s.Keys...)
+		sort.Ints(keys)
+		id := fmt.Sprint(keys)
+		byChord[id] = append(byChord[id], s.Action)
+		if _, ok := display[id]; !ok {
+			display[id] = s.Chord()
+		}
+	}
+
+	var conflicts []ShortcutConflict
+	for id, actions := range byChord {
+		if len(actions) > 1 {
+			sort.Strings(actions)
+			conflicts = append(conflicts, ShortcutConflict{Chord: display[id], Actions: actions})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Chord < conflicts[j].Chord
+	})
+	return conflicts
+}
+
+// NewShortcutFile returns the human-editable form of shortcuts.
+func NewShortcutFile(shortcuts []Shortcut) *ShortcutFile {
+	sf := &ShortcutFile{Shortcuts: make(map[string]string, len(shortcuts))}
+	for _, s := range shortcuts {
+		sf.Shortcuts[s.Action] = s.Chord()
+	}
+	return sf
+}
+
+// Bindings parses the chords of the file into shortcuts, sorted by action.
+func (sf *ShortcutFile) Bindings() ([]Shortcut, error) {
+	shortcuts := make([]Shortcut, 0, len(sf.Shortcuts))
+	for action, chord := range sf.Shortcuts {
+		keys, err := ParseChord(chord)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binding for %s: %w", action, err)
+		}
+		shortcuts = append(shortcuts, Shortcut{Action: action, Keys: keys})
+	}
+
+	sort.Slice(shortcuts, func(i, j int) bool {
+		return shortcuts[i].Action < shortcuts[j].Action
+	})
+	return shortcuts, nil
+}
+
+// ShortcutFormat returns the file format ("toml" or "json") implied by the
+// extension of path, defaulting to TOML.
+func ShortcutFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "toml"
+}
+
+// Encode renders the shortcut file in the given format ("toml" or "json").
+func (sf *ShortcutFile) Encode(format string) ([]byte, error) {
+	switch format {
+	case "toml":
+		var buf bytes.Buffer
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = ""
+		if err := enc.Encode(sf); err != nil {
+			return nil, fmt.Errorf("failed to encode TOML: %w", err)
+		}
+		return buf.Bytes(), nil
+	case "json":
+		data, err := json.MarshalIndent(sf, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return append(data, '\n'), nil
+	}
+	return nil, fmt.Errorf("unsupported format '%s' (use toml or json)", format)
+}
+
+// LoadShortcutFile reads a TOML or JSON shortcut file, chosen by extension.
+func LoadShortcutFile(path string) (*ShortcutFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shortcut file: %w", err)
+	}
+
+	var sf ShortcutFile
+	if ShortcutFormat(path) == "json" {
+		err = json.Unmarshal(data, &sf)
+	} else {
+		err = toml.Unmarshal(data, &sf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shortcut file %s: %w", path, err)
+	}
+
+	if len(sf.Shortcuts) == 0 {
+		return nil, fmt.Errorf("shortcut file %s contains no shortcuts", path)
+	}
+	return &sf, nil
+}
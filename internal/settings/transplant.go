@@ -0,0 +1,83 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/jpbriend/eve-settings-manager/internal/marshal"
+)
+
+// Transplant copies top-level sections from src into dst.
+//
+// With only set, dst keeps everything except the listed sections, which are
+// replaced by the source's. Every listed section must exist in the source.
+// With exclude set, dst receives all source sections except the listed ones,
+// for which it keeps its own (if any). Exactly one of only and exclude must
+// be non-empty. It returns the names of the sections copied from src.
+func Transplant(src, dst *File, only, exclude []string) ([]string, error) {
+	if (len(only) == 0) == (len(exclude) == 0) {
+		return nil, fmt.Errorf("exactly one of only and exclude must be given")
+	}
+
+	srcSections, err := src.Sections()
+	if err != nil {
+		return nil, err
+	}
+	dstSections, err := dst.Sections()
+	if err != nil {
+		return nil, err
+	}
+
+	var copied []string
+	result := &marshal.Dict{}
+
+	if len(only) > 0 {
+		wanted := make(map[string]bool, len(only))
+		for _, name := range only {
+			if _, ok := lookup(srcSections, name); !ok {
+				return nil, fmt.Errorf("section '%s' not found in source settings", name)
+			}
+			wanted[name] = true
+		}
+
+		// Keep the target's layout, swapping in the wanted sections
+		placed := make(map[string]bool, len(only))
+		for _, e := range dstSections.Entries {
+			name := KeyString(e.Key)
+			if wanted[name] {
+				e.Value, _ = lookup(srcSections, name)
+				placed[name] = true
+				copied = append(copied, name)
+			}
+			result.Entries = append(result.Entries, e)
+		}
+		for _, e := range srcSections.Entries {
+			name := KeyString(e.Key)
+			if wanted[name] && !placed[name] {
+				result.Entries = append(result.Entries, e)
+				copied = append(copied, name)
+			}
+		}
+	} else {
+		excluded := make(map[string]bool, len(exclude))
+		for _, name := range exclude {
+			excluded[name] = true
+		}
+
+		// Take the source's layout, keeping the target's excluded sections
+		for _, e := range srcSections.Entries {
+			name := KeyString(e.Key)
+			if !excluded[name] {
+				result.Entries = append(result.Entries, e)
+				copied = append(copied, name)
+			}
+		}
+		for _, e := range dstSections.Entries {
+			if excluded[KeyString(e.Key)] {
+				result.Entries = append(result.Entries, e)
+			}
+		}
+	}
+
+	dst.Root = result
+	return copied, nil
+}